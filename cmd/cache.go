@@ -1,12 +1,18 @@
 package cmd
 
 import (
+	"bytes"
+	"fmt"
 	"os"
 	"path/filepath"
+	"text/tabwriter"
+	"time"
 
 	"github.com/urfave/cli/v3"
 
+	"github.com/serious-snow/govm/pkg/manager"
 	"github.com/serious-snow/govm/pkg/utils/path"
+	"github.com/serious-snow/govm/pkg/version"
 )
 
 func cacheCommand() *cli.Command {
@@ -14,8 +20,82 @@ func cacheCommand() *cli.Command {
 		Name:      "cache",
 		Aliases:   []string{"c"},
 		Usage:     "Cache manager",
-		UsageText: getCmdLine("cache", "[dir]", "[clear]"),
+		UsageText: getCmdLine("cache", "[dir]", "[list]", "[rm]", "[prune]", "[verify]", "[clear]", "[size]"),
 		Commands: []*cli.Command{
+			{
+				Name:    "list",
+				Aliases: []string{"ls"},
+				Usage:   "List cached archives",
+				Action: func(c *cli.Context) error {
+					printCacheList()
+					return nil
+				},
+			},
+			{
+				Name:          "rm",
+				Usage:         "Remove cached archives of versions",
+				UsageText:     getCmdLine("cache", "rm", "<version>..."),
+				ShellComplete: completeArgs(0, cachedCandidates),
+				Action: func(c *cli.Context) error {
+					if c.NArg() == 0 {
+						return cli.ShowSubcommandHelp(c)
+					}
+					removeCache(c.Args().Slice())
+					return nil
+				},
+			},
+			{
+				Name:      "prune",
+				Usage:     "Remove stale and least recently used archives",
+				UsageText: getCmdLine("cache", "prune", "[--keep-installed]", "[--older-than <90d>]", "[--max-size <2GB>]", "[--dry-run]"),
+				Description: "unfinished downloads (.temp) not modified for an hour are always removed,\n" +
+					"archives are used again when installing, so least recently used ones are removed first",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:    "keep-installed",
+						Aliases: []string{"k"},
+						Usage:   "keep archives of installed versions",
+					},
+					&cli.StringFlag{
+						Name:  "older-than",
+						Usage: "remove archives not used for the duration, such as 90d, 720h",
+					},
+					&cli.StringFlag{
+						Name:  "max-size",
+						Usage: "remove least recently used archives until the cache is smaller than the size, such as 2GB",
+					},
+					&cli.BoolFlag{
+						Name:    "dry-run",
+						Aliases: []string{"n"},
+						Usage:   "print archives to remove without removing them",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					opts := manager.PruneOptions{
+						KeepInstalled: c.Bool("keep-installed"),
+						DryRun:        c.Bool("dry-run"),
+					}
+					if s := c.String("older-than"); s != "" {
+						d, err := parseDuration(s)
+						if err != nil {
+							printError("--older-than 格式错误：" + err.Error())
+							return nil
+						}
+						opts.OlderThan = d
+					}
+					if s := c.String("max-size"); s != "" {
+						size, err := parseSize(s)
+						if err != nil {
+							printError("--max-size 格式错误：" + err.Error())
+							return nil
+						}
+						opts.MaxSize = size
+					}
+					pruneCache(opts)
+					return nil
+				},
+			},
+			cacheVerifyCommand(),
 			{
 				Name:  "dir",
 				Usage: "Print cache dir",
@@ -91,3 +171,105 @@ func cacheCommand() *cli.Command {
 		},
 	}
 }
+
+func printCacheList() {
+	entries, err := mgr.CacheEntries()
+	if err != nil {
+		printError("读取缓存目录失败：" + err.Error())
+		return
+	}
+	if len(entries) == 0 {
+		Println("没有缓存的安装包")
+		return
+	}
+
+	buf := bytes.Buffer{}
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "VERSION\tPLATFORM\tSIZE\tAGE\tSHA256\tINSTALLED\tFILE")
+	for _, entry := range entries {
+		installed := "-"
+		if entry.Host() && isInstall(entry.Version) {
+			installed = "yes"
+		}
+		// 显示最近一次校验的结果，不重新计算 sha256，校验由 cache verify 执行
+		status := mgr.CachedStatus(entry)
+		if status == manager.CacheOK {
+			status = "verified"
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			entry.Version, entry.Platform, formatSize(entry.Size), formatAge(time.Since(entry.ModTime)),
+			status, installed, entry.Name)
+	}
+	_ = w.Flush()
+	Print(buf.String())
+}
+
+// formatAge 如 3d、5h、10m
+func formatAge(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour:
+		return fmt.Sprintf("%dd", int(d/(24*time.Hour)))
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh", int(d/time.Hour))
+	default:
+		return fmt.Sprintf("%dm", int(d/time.Minute))
+	}
+}
+
+func removeCache(versions []string) {
+	for _, ver := range versions {
+		removed, err := mgr.RemoveCache(*version.New(manager.TrimVersion(ver)))
+		for _, entry := range removed {
+			Println("已删除", entry.Name)
+		}
+		if err != nil {
+			printError("删除缓存文件失败：" + err.Error())
+			continue
+		}
+		if len(removed) == 0 {
+			Println(ver, "没有缓存的安装包")
+		}
+	}
+}
+
+func pruneCache(opts manager.PruneOptions) {
+	removed, err := mgr.PruneCache(opts)
+	size := int64(0)
+	for _, entry := range removed {
+		size += entry.Size
+		if opts.DryRun {
+			Println("将删除", entry.Name, formatSize(entry.Size))
+		} else {
+			Println("已删除", entry.Name, formatSize(entry.Size))
+		}
+	}
+	if err != nil {
+		printError("清理缓存失败：" + err.Error())
+		return
+	}
+	if opts.DryRun {
+		Printf("共 %d 个文件，%s\n", len(removed), formatSize(size))
+		return
+	}
+	Printf("共删除 %d 个文件，释放 %s\n", len(removed), formatSize(size))
+}
+
+// enforceCacheMaxSize 缓存超出配置的大小上限时，按最近最少使用的顺序删除归档文件
+func enforceCacheMaxSize() {
+	if conf.CacheMaxSize == "" {
+		return
+	}
+	maxSize, err := parseSize(conf.CacheMaxSize)
+	if err != nil {
+		printError("cacheMaxSize 配置错误：" + err.Error())
+		return
+	}
+	removed, err := mgr.PruneCache(manager.PruneOptions{MaxSize: maxSize})
+	if err != nil {
+		printError("清理缓存失败：" + err.Error())
+		return
+	}
+	for _, entry := range removed {
+		Println("缓存超出", conf.CacheMaxSize+"，已删除", entry.Name)
+	}
+}
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v3"
+
+	"github.com/serious-snow/govm/pkg/manager"
+)
+
+func cacheVerifyCommand() *cli.Command {
+	return &cli.Command{
+		Name:        "verify",
+		Usage:       "Verify sha256 of cached archives",
+		UsageText:   getCmdLine("cache", "verify", "[--jobs <n>]", "[--repair]", "[--json]"),
+		Description: "exits with 1 if any archive does not match and is not repaired",
+		Flags: []cli.Flag{
+			&cli.IntFlag{
+				Name:    "jobs",
+				Aliases: []string{"j"},
+				Usage:   "number of archives verified at the same time",
+				Value:   3,
+			},
+			&cli.BoolFlag{
+				Name:    "repair",
+				Aliases: []string{"r"},
+				Usage:   "remove archives that do not match and download them again",
+			},
+			&cli.BoolFlag{
+				Name:  "json",
+				Usage: "print the result as json",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			return verifyCache(c.Context, int(c.Int("jobs")), c.Bool("repair"), c.Bool("json"))
+		},
+	}
+}
+
+// cacheVerifyResult 单个归档文件的校验结果，也用于 json 输出
+type cacheVerifyResult struct {
+	Version  string `json:"version"`
+	Platform string `json:"platform"`
+	File     string `json:"file"`
+	Size     int64  `json:"size"`
+	Status   string `json:"status"`
+	// Repair 修复结果：repaired 已重新下载，failed 修复失败，为空时未修复
+	Repair string `json:"repair,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+func verifyCache(ctx context.Context, jobs int, repair, asJSON bool) error {
+	checks, err := mgr.VerifyCache(jobs)
+	if err != nil {
+		printError("读取缓存目录失败：" + err.Error())
+		return cli.Exit("", 1)
+	}
+
+	results := make([]*cacheVerifyResult, 0, len(checks))
+	broken := 0
+	for _, check := range checks {
+		result := &cacheVerifyResult{
+			Version:  check.Entry.Version.String(),
+			Platform: check.Entry.Platform,
+			File:     check.Entry.Name,
+			Size:     check.Entry.Size,
+			Status:   string(check.Status),
+		}
+		results = append(results, result)
+		if check.Status != manager.CacheMismatch {
+			continue
+		}
+		if !repair {
+			broken++
+			continue
+		}
+		// json 输出时不输出下载过程，避免破坏 json 格式
+		if err := mgr.RepairCache(ctx, check.Entry, manager.FetchOptions{Quiet: asJSON}); err != nil {
+			result.Repair = "failed"
+			result.Error = err.Error()
+			broken++
+			continue
+		}
+		result.Repair = "repaired"
+	}
+
+	if asJSON {
+		data, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			printError("序列化校验结果失败：" + err.Error())
+			return cli.Exit("", 1)
+		}
+		Println(string(data))
+	} else {
+		printVerifyResults(results)
+	}
+
+	if broken != 0 {
+		return cli.Exit("", 1)
+	}
+	return nil
+}
+
+func printVerifyResults(results []*cacheVerifyResult) {
+	if len(results) == 0 {
+		Println("没有缓存的安装包")
+		return
+	}
+
+	buf := bytes.Buffer{}
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "VERSION\tPLATFORM\tSIZE\tSTATUS\tREPAIR\tFILE")
+	count := map[string]int{}
+	for _, result := range results {
+		repair := result.Repair
+		if repair == "" {
+			repair = "-"
+		}
+		count[result.Status]++
+		count[result.Repair]++
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			result.Version, result.Platform, formatSize(result.Size), result.Status, repair, result.File)
+	}
+	_ = w.Flush()
+	Print(buf.String())
+
+	for _, result := range results {
+		if result.Error != "" {
+			printError(result.File + " 修复失败：" + result.Error)
+		}
+	}
+
+	summary := fmt.Sprintf("共 %d 个文件：通过 %d，不匹配 %d（已修复 %d），无法校验 %d，未下载完成 %d",
+		len(results), count[string(manager.CacheOK)], count[string(manager.CacheMismatch)], count["repaired"],
+		count[string(manager.CacheUnknown)], count[string(manager.CachePartial)])
+	if count[string(manager.CacheMismatch)] != count["repaired"] {
+		summary = color.YellowString(summary)
+	}
+	Println(summary)
+}
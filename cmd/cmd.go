@@ -9,13 +9,16 @@ import (
 	"path/filepath"
 	"runtime"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/manifoldco/promptui"
 	"github.com/urfave/cli/v3"
 
 	"github.com/serious-snow/govm/config"
+	"github.com/serious-snow/govm/pkg/manager"
 	"github.com/serious-snow/govm/pkg/utils/path"
 	"github.com/serious-snow/govm/pkg/version"
 )
@@ -34,11 +37,14 @@ var (
 
 var (
 	conf                 config.Config
+	mgr                  *manager.Manager
 	homeDir              string
 	processDir           string
 	remoteVersion        RemoteVersion
 	localInstallVersions []*version.Version
-	holdVersions         []string
+	// installMetas 已安装版本的来源信息，旧版本 govm 安装的版本没有记录
+	installMetas map[string]*manager.InstallMeta
+	holdVersions []manager.Hold
 
 	currentUse version.Version
 
@@ -87,6 +93,16 @@ func Run() error {
 	linkPath = filepath.Join(processDir, "go")
 	envPath = filepath.Join(linkPath, "bin")
 
+	mgr = manager.New(manager.Config{
+		CachePath:   conf.CachePath,
+		JournalPath: filepath.Join(processDir, "upgrade.json"),
+		Source:      manager.NewDLSource(downloadLink),
+		Store:       manager.NewFileStore(conf.InstallPath, conf.CachePath, conf.Dedupe),
+		Linker:      manager.NewSymlinkLinker(linkPath, Symlink),
+		Logf:        Printf,
+		GovmVersion: Version,
+	})
+
 	{
 		app = &cli.Command{
 			Name:        pName,
@@ -125,7 +141,22 @@ func Run() error {
 				upgradeCommand(),
 				holdCommand(),
 				unholdCommand(),
+				shellCommand(),
+				envCommand(),
+				outdatedCommand(),
+				notesCommand(),
+				fetchCommand(),
+				verifyCommand(),
+				infoCommand(),
+				duCommand(),
+				dedupeCommand(),
+				gcCommand(),
+				projectsCommand(),
+				historyCommand(),
+				repairCommand(),
+				completionCommand(),
 			},
+			EnableShellCompletion:  true,
 			UseShortOptionHandling: true,
 			Suggest:                true,
 			Reader:                 os.Stdin,
@@ -137,11 +168,22 @@ func Run() error {
 		sort.Slice(app.Commands, func(i, j int) bool {
 			return app.Commands[i].Name < app.Commands[j].Name
 		})
+
+		// 子命令需要同时开启才会调用各自的 ShellComplete
+		if isCompletion() {
+			enableShellCompletion(app.Commands)
+		}
 	}
 
-	{
+	// 补全时不检查环境变量，也不处理中断的升级，避免交互和副作用
+	if !isCompletion() {
 		// 检查环境变量
 		initEnvPath()
+		// 处理上次中断的升级
+		recoverUpgrade()
+	}
+
+	{
 		// 读取本地安装版本
 		readLocalInstallVersion()
 		// 读取本地缓存列表
@@ -152,7 +194,21 @@ func Run() error {
 		readLocalHoldVersion()
 	}
 
-	return app.Run(context.Background(), os.Args)
+	if isCompletion() {
+		if flags, ok := completeFlags(app, os.Args[1:len(os.Args)-1]); ok {
+			for _, flag := range flags {
+				Println(flag)
+			}
+			return nil
+		}
+	}
+
+	args := os.Args
+	if isCompletion() && completionHasCommand() {
+		// 根命令会去掉补全参数，再追加一次以传递给子命令
+		args = append(args, completionFlag)
+	}
+	return app.Run(context.Background(), args)
 }
 
 func printEnv() string {
@@ -198,6 +254,13 @@ func readLocalRemoteVersion() {
 		// If that fails, try unmarshaling into just the Go field for backward compatibility
 		_ = json.Unmarshal(buf, &remoteVersion.Go)
 	}
+	// 旧格式没有 stable 标记，根据是否为 rc、beta 判断，执行 update 后会更新为 go.dev 的标记
+	if remoteVersion.Schema < remoteVersionSchema {
+		for _, info := range remoteVersion.Go {
+			info.Stable = !info.Version.RC && !info.Version.Beta
+		}
+	}
+	mgr.SetRemote(remoteVersion.Go)
 }
 
 func saveLocalRemoteVersion() error {
@@ -207,6 +270,7 @@ func saveLocalRemoteVersion() error {
 		return err
 	}
 	defer file.Close()
+	remoteVersion.Schema = remoteVersionSchema
 	if err := json.NewEncoder(file).Encode(remoteVersion); err != nil {
 		return err
 	}
@@ -214,46 +278,17 @@ func saveLocalRemoteVersion() error {
 }
 
 func readLocalHoldVersion() {
-	filename := filepath.Join(conf.CachePath, "hold.json")
-	buf, err := os.ReadFile(filename)
-	if err != nil {
-		return
-	}
-	holdVersions = make([]string, 0)
-	_ = json.Unmarshal(buf, &holdVersions)
-}
-
-func saveLocalHoldVersion() error {
-	filename := filepath.Join(conf.CachePath, "hold.json")
-	file, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-	if err := json.NewEncoder(file).Encode(holdVersions); err != nil {
-		return err
-	}
-	return nil
+	holdVersions = mgr.Holds()
 }
 
 func readLocalInstallVersion() {
-	fileInfoList, err := os.ReadDir(conf.InstallPath)
-	if err != nil {
-		return
-	}
-	localInstallVersions = make([]*version.Version, 0)
-	for _, info := range fileInfoList {
-		if !info.IsDir() {
-			continue
-		}
-
-		vInfo := version.New(info.Name())
-		if vInfo.Valid() {
-			localInstallVersions = append(localInstallVersions, vInfo)
+	localInstallVersions = mgr.List(true)
+	installMetas = make(map[string]*manager.InstallMeta, len(localInstallVersions))
+	for _, v := range localInstallVersions {
+		if meta := mgr.Meta(*v); meta != nil {
+			installMetas[v.String()] = meta
 		}
 	}
-
-	version.SortV(localInstallVersions).Reverse()
 }
 
 func printError(msg string) {
@@ -286,31 +321,7 @@ func isInInstall(ver string) bool {
 }
 
 func readCurrentUseVersion() {
-	to, err := os.Readlink(linkPath)
-	if err != nil {
-		return
-	}
-
-	// Use filepath to properly handle path separators
-	relPath, err := filepath.Rel(conf.InstallPath, to)
-	if err != nil {
-		return
-	}
-
-	// Clean the path and extract version
-	versionStr := filepath.Base(relPath)
-	versionStr = strings.TrimPrefix(versionStr, "go")
-	v := version.New(versionStr)
-	if v != nil {
-		currentUse = *v
-	}
-}
-
-func trimVersion(version string) string {
-	version = strings.TrimSpace(version)
-	version = strings.TrimPrefix(version, "go")
-	version = strings.TrimPrefix(version, "v")
-	return version
+	currentUse, _ = mgr.Current()
 }
 
 func getCmdLine(cmd ...string) string {
@@ -333,6 +344,44 @@ func formatSize(size int64) string {
 	return fmt.Sprintf("%.2f%s", fSize, units[idx])
 }
 
+// parseSize 解析大小，如 2GB、500MB、1.5G，不带单位时为字节
+func parseSize(s string) (int64, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	units := []struct {
+		suffix string
+		size   float64
+	}{
+		{"GB", 1 << 30}, {"G", 1 << 30},
+		{"MB", 1 << 20}, {"M", 1 << 20},
+		{"KB", 1 << 10}, {"K", 1 << 10},
+		{"B", 1},
+	}
+	multiple := float64(1)
+	for _, unit := range units {
+		if strings.HasSuffix(s, unit.suffix) {
+			s, multiple = strings.TrimSuffix(s, unit.suffix), unit.size
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("无效的大小：%s", s)
+	}
+	return int64(n * multiple), nil
+}
+
+// parseDuration 在 time.ParseDuration 的基础上支持天，如 90d
+func parseDuration(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("无效的时长：%s", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(s)
+}
+
 func initEnvPath() {
 	if strings.Contains(os.Getenv("PATH"), envPath) {
 		return
@@ -365,13 +414,14 @@ func initEnvPath() {
 	}
 }
 
+// getDownloadFilename 本机平台的归档文件在缓存目录中的路径
 func getDownloadFilename(version string) string {
 	suffix := "tar.gz"
 	if isWin {
 		suffix = "zip"
 	}
 
-	return fmt.Sprintf("go%s.%s-%s.%s", version, runtime.GOOS, runtime.GOARCH, suffix)
+	return filepath.Join(conf.CachePath, fmt.Sprintf("go%s.%s-%s.%s", version, runtime.GOOS, runtime.GOARCH, suffix))
 }
 
 type Action uint16
@@ -382,53 +432,27 @@ const (
 	ActionExec
 )
 
+// resolveAlias 将 latest、stable 别名解析为具体版本，安装时从远程列表中选择，
+// 否则从已安装的版本中选择，解析失败时原样返回
+func resolveAlias(ver string, action Action) string {
+	if !manager.IsAlias(ver) {
+		return ver
+	}
+	v, err := mgr.Alias(ver, action == ActionInstall)
+	if err != nil {
+		return ver
+	}
+	return v.String()
+}
+
 func suggestVersion(ver string, action Action) string {
 	if flagNoSuggest {
 		return ""
 	}
-	v := version.New(ver)
 
-	minorVersion := v.MinorVersion()
-	if v.String() != minorVersion {
+	v, err := mgr.Resolve(ver, action == ActionInstall)
+	if err != nil {
 		return ""
 	}
-	ver = ""
-	switch action {
-	case ActionInstall:
-		versions := make([]*version.Version, 0, len(remoteVersion.Go))
-		for _, info := range remoteVersion.Go {
-			versions = append(versions, &info.Version)
-		}
-		vls := GetMinorGroup(versions)[minorVersion]
-		for _, ve := range vls {
-			if isInInstall(ve.String()) {
-				ver = ve.String()
-				break
-			}
-		}
-		if ver == "" {
-			if len(vls) != 0 {
-				ver = vls[0].String()
-			}
-		}
-
-	case ActionUse, ActionExec:
-		vls := GetMinorGroup(localInstallVersions)[minorVersion]
-		for _, ve := range vls {
-			if isInInstall(ve.String()) {
-				ver = ve.String()
-				break
-			}
-		}
-	}
-
-	return ver
-}
-
-func GetMinorGroup(list []*version.Version) map[string][]*version.Version {
-	m := make(map[string][]*version.Version)
-	for _, v := range list {
-		m[v.MinorVersion()] = append(m[v.MinorVersion()], v)
-	}
-	return m
+	return v.String()
 }
@@ -0,0 +1,249 @@
+package cmd
+
+import (
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/serious-snow/govm/pkg/manager"
+	"github.com/serious-snow/govm/pkg/version"
+)
+
+const completionFlag = "--generate-shell-completion"
+
+const bashCompletion = `# bash completion for %[1]s
+_%[2]s_complete() {
+  local cur opts
+  local -a words
+  COMPREPLY=()
+  cur="${COMP_WORDS[COMP_CWORD]}"
+  words=("${COMP_WORDS[@]:0:COMP_CWORD}")
+  if [[ "$cur" == -* ]]; then
+    opts=$("${words[@]}" "$cur" ` + completionFlag + ` 2>/dev/null)
+  else
+    opts=$("${words[@]}" ` + completionFlag + ` 2>/dev/null)
+  fi
+  COMPREPLY=($(compgen -W "${opts}" -- "${cur}"))
+}
+complete -o default -F _%[2]s_complete %[1]s
+`
+
+const zshCompletion = `#compdef %[1]s
+_%[2]s_complete() {
+  local -a opts
+  local cur=${words[CURRENT]}
+  if [[ "$cur" == -* ]]; then
+    opts=("${(@f)$(${words[1,CURRENT-1]} $cur ` + completionFlag + ` 2>/dev/null)}")
+  else
+    opts=("${(@f)$(${words[1,CURRENT-1]} ` + completionFlag + ` 2>/dev/null)}")
+  fi
+  if [[ "${opts[1]}" != "" ]]; then
+    _describe 'values' opts
+  else
+    _files
+  fi
+}
+compdef _%[2]s_complete %[1]s
+`
+
+const fishCompletion = `# fish completion for %[1]s
+function __%[2]s_complete
+    set -l tokens (commandline -opc)
+    set -l cur (commandline -ct)
+    if string match -q -- '-*' $cur
+        command $tokens $cur ` + completionFlag + ` 2>/dev/null
+    else
+        command $tokens ` + completionFlag + ` 2>/dev/null
+    end
+end
+complete -c %[1]s -f -a '(__%[2]s_complete)'
+`
+
+const pwshCompletion = `# PowerShell completion for %[1]s
+Register-ArgumentCompleter -Native -CommandName '%[1]s' -ScriptBlock {
+    param($wordToComplete, $commandAst, $cursorPosition)
+    $words = @($commandAst.CommandElements | Where-Object { $_.Extent.StartOffset -lt $cursorPosition } | ForEach-Object { $_.ToString() })
+    if ($wordToComplete -ne '' -and -not $wordToComplete.StartsWith('-')) {
+        $words = @($words | Select-Object -SkipLast 1)
+    }
+    $rest = @()
+    if ($words.Count -gt 1) { $rest = $words[1..($words.Count - 1)] }
+    & $words[0] @rest ` + completionFlag + ` 2>$null |
+        Where-Object { $_ -like "$wordToComplete*" } |
+        ForEach-Object { [System.Management.Automation.CompletionResult]::new($_, $_, 'ParameterValue', $_) }
+}
+`
+
+var completionScripts = map[string]string{
+	"bash": bashCompletion,
+	"zsh":  zshCompletion,
+	"fish": fishCompletion,
+	"pwsh": pwshCompletion,
+}
+
+func completionCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "completion",
+		Usage:     "Print shell completion script",
+		UsageText: getCmdLine("completion", "bash|zsh|fish|pwsh"),
+		Description: "for example:\n" +
+			"  source <(" + getCmdLine("completion", "bash") + ")\n" +
+			"  " + getCmdLine("completion", "fish") + " | source",
+		ShellComplete: completeArgs(1, func() []string {
+			return []string{"bash", "zsh", "fish", "pwsh"}
+		}),
+		Action: func(c *cli.Context) error {
+			shell := c.Args().Get(0)
+			if shell == "" {
+				shell = defaultShell()
+			}
+			script, ok := completionScripts[shell]
+			if !ok {
+				printError("不支持的 shell：" + shell + "，支持：bash, zsh, fish, pwsh")
+				return nil
+			}
+			funcName := regexp.MustCompile(`\W`).ReplaceAllString(pName, "_")
+			Printf(script, pName, funcName)
+			return nil
+		},
+	}
+}
+
+// isCompletion 是否为 shell 补全调用，补全时不能有交互和网络请求
+func isCompletion() bool {
+	return len(os.Args) > 1 && os.Args[len(os.Args)-1] == completionFlag
+}
+
+// completionHasCommand 补全的命令行中是否包含子命令
+func completionHasCommand() bool {
+	for _, arg := range os.Args[1 : len(os.Args)-1] {
+		if !strings.HasPrefix(arg, "-") {
+			return true
+		}
+	}
+	return false
+}
+
+func enableShellCompletion(commands []*cli.Command) {
+	for _, c := range commands {
+		c.EnableShellCompletion = true
+		enableShellCompletion(c.Commands)
+	}
+}
+
+// completeFlags 当前输入以 - 开头时补全子命令的参数名，返回是否为参数名补全，
+// 不能交给 cli 处理，未知或不完整的参数名会导致其解析失败后 panic
+func completeFlags(root *cli.Command, args []string) ([]string, bool) {
+	if len(args) == 0 || !strings.HasPrefix(args[len(args)-1], "-") {
+		return nil, false
+	}
+	cmd := root
+	for _, arg := range args[:len(args)-1] {
+		if sub := cmd.Command(arg); sub != nil {
+			cmd = sub
+		}
+	}
+
+	last := args[len(args)-1]
+	cur := strings.TrimLeft(last, "-")
+	result := make([]string, 0)
+	for _, flag := range cmd.Flags {
+		for _, name := range flag.Names() {
+			// 输入 -- 时不补全单字母的短参数名
+			if len(name) == 1 && strings.HasPrefix(last, "--") {
+				continue
+			}
+			if !strings.HasPrefix(name, cur) || name == cur {
+				continue
+			}
+			if len(name) == 1 {
+				result = append(result, "-"+name)
+			} else {
+				result = append(result, "--"+name)
+			}
+		}
+	}
+	return result, true
+}
+
+// completeArgs 补全参数，最多补全 max 个参数，max 为 0 时不限制
+func completeArgs(max int, candidates func() []string) cli.ShellCompleteFunc {
+	return func(c *cli.Context) {
+		if max > 0 && c.NArg() >= max {
+			return
+		}
+		given := make(map[string]bool, c.NArg())
+		for _, arg := range c.Args().Slice() {
+			given[arg] = true
+		}
+		for _, s := range candidates() {
+			if !given[s] {
+				Println(s)
+			}
+		}
+	}
+}
+
+func versionStrings(vs []*version.Version) []string {
+	result := make([]string, 0, len(vs))
+	for _, v := range vs {
+		result = append(result, v.String())
+	}
+	return result
+}
+
+// installedCandidates 已安装的版本
+func installedCandidates() []string {
+	return versionStrings(localInstallVersions)
+}
+
+// useCandidates 已安装的版本和版本别名
+func useCandidates() []string {
+	return append(installedCandidates(), manager.AliasLatest, manager.AliasStable)
+}
+
+// remoteCandidates 本地缓存的远程版本列表，补全时不会请求网络
+func remoteCandidates() []string {
+	result := make([]string, 0, len(remoteVersion.Go))
+	for _, info := range remoteVersion.Go {
+		result = append(result, info.Version.String())
+	}
+	return append(result, manager.AliasLatest, manager.AliasStable)
+}
+
+// uninstallCandidates 已安装且未激活的版本
+func uninstallCandidates() []string {
+	result := make([]string, 0, len(localInstallVersions))
+	for _, v := range localInstallVersions {
+		if v.Equal(currentUse) {
+			continue
+		}
+		result = append(result, v.String())
+	}
+	return result
+}
+
+// cachedCandidates 有缓存安装包的版本
+func cachedCandidates() []string {
+	entries, _ := mgr.CacheEntries()
+	result := make([]string, 0, len(entries))
+	seen := map[string]bool{}
+	for _, entry := range entries {
+		if v := entry.Version.String(); !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// holdCandidates 被保留的版本或模式
+func holdCandidates() []string {
+	result := make([]string, 0, len(holdVersions))
+	for _, h := range holdVersions {
+		result = append(result, h.Pattern)
+	}
+	return result
+}
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/urfave/cli/v3"
+)
+
+func TestCompleteFlags(t *testing.T) {
+	root := &cli.Command{
+		Name: "govm",
+		Commands: []*cli.Command{
+			{
+				Name:    "install",
+				Aliases: []string{"i"},
+				Flags: []cli.Flag{
+					&cli.BoolFlag{Name: "force", Aliases: []string{"f"}},
+					&cli.BoolFlag{Name: "slim"},
+					&cli.StringFlag{Name: "slim-profile"},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		args []string
+		want []string
+		ok   bool
+	}{
+		{[]string{"install", "--s"}, []string{"--slim", "--slim-profile"}, true},
+		{[]string{"i", "-"}, []string{"--force", "-f", "--slim", "--slim-profile"}, true},
+		{[]string{"install", "--x"}, []string{}, true},
+		{[]string{"install", "1.2"}, nil, false},
+		{nil, nil, false},
+	}
+	for _, tt := range tests {
+		got, ok := completeFlags(root, tt.args)
+		if ok != tt.ok || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("completeFlags(%v) = %v, %v, want %v, %v", tt.args, got, ok, tt.want, tt.ok)
+		}
+	}
+}
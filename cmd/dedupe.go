@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"github.com/urfave/cli/v3"
+
+	"github.com/serious-snow/govm/pkg/manager"
+)
+
+func dedupeCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "dedupe",
+		Usage:     "Hardlink identical files across installed versions",
+		UsageText: getCmdLine("dedupe", "[<version>...]"),
+		Description: "dedupes all installed versions if no <version> is given,\n" +
+			"set dedupe: true in the config to dedupe new installs automatically",
+		ShellComplete: completeArgs(0, installedCandidates),
+		Action: func(c *cli.Context) error {
+			versions := c.Args().Slice()
+			if len(versions) == 0 {
+				versions = installedCandidates()
+			}
+			if len(versions) == 0 {
+				Println("没有已安装的版本")
+				return nil
+			}
+			dedupeVersions(versions)
+			return nil
+		},
+	}
+}
+
+func dedupeVersions(versions []string) {
+	total := manager.DedupeResult{}
+	for _, ver := range versions {
+		ver = manager.TrimVersion(ver)
+		result, err := mgr.Dedupe(ver)
+		if err != nil {
+			printError(ver + " 去重失败：" + err.Error())
+			continue
+		}
+		Printf("%s 合并了 %d 个文件，节省 %s\n", ver, result.Files, formatSize(result.Saved))
+		total.Files += result.Files
+		total.Saved += result.Saved
+	}
+	Printf("共合并 %d 个文件，节省 %s\n", total.Files, formatSize(total.Saved))
+	if !conf.Dedupe {
+		Println("如需安装时自动去重，在配置文件中设置 dedupe: true")
+	}
+}
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/serious-snow/govm/pkg/utils/path"
+)
+
+func duCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "du",
+		Usage:     "Show disk usage of installed versions and cached archives",
+		UsageText: getCmdLine("du"),
+		Description: "APPARENT is the total size of the files of a version,\n" +
+			"REAL is the space freed by uninstalling it, files shared by hardlinks are counted once in TOTAL",
+		Action: func(c *cli.Context) error {
+			printDiskUsage()
+			return nil
+		},
+	}
+}
+
+func printDiskUsage() {
+	entries, err := mgr.CacheEntries()
+	if err != nil {
+		printError("读取缓存目录失败：" + err.Error())
+	}
+
+	// 本机平台的归档文件与已安装版本显示在同一行
+	cached := map[string]int64{}
+	others := make([]string, 0)
+	otherSizes := map[string]int64{}
+	for _, entry := range entries {
+		if entry.Host() {
+			cached[entry.Version.String()] += entry.Size
+			continue
+		}
+		name := fmt.Sprintf("%s (%s)", entry.Version, entry.Platform)
+		if _, ok := otherSizes[name]; !ok {
+			others = append(others, name)
+		}
+		otherSizes[name] += entry.Size
+	}
+
+	buf := bytes.Buffer{}
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "VERSION\tAPPARENT\tREAL\tCACHED\tPATH")
+	totalApparent, totalCached := int64(0), int64(0)
+	cacheSize := func(size int64, ok bool) string {
+		if !ok {
+			return "-"
+		}
+		totalCached += size
+		return formatSize(size)
+	}
+
+	// seen 记录已统计的 inode，硬链接共享的文件在总计中只统计一次
+	seen := map[uint64]bool{}
+	totalReal := int64(0)
+	for _, v := range localInstallVersions {
+		dir := filepath.Dir(mgr.GoRoot(*v))
+		apparent, freed, unique, err := diskUsage(dir, seen)
+		if err != nil {
+			printError("统计 " + v.String() + " 的磁盘占用失败：" + err.Error())
+		}
+		totalApparent += apparent
+		totalReal += unique
+		size, ok := cached[v.String()]
+		delete(cached, v.String())
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", v, formatSize(apparent), formatSize(freed), cacheSize(size, ok), dir)
+	}
+	// 只有缓存没有安装的版本
+	for _, entry := range entries {
+		size, ok := cached[entry.Version.String()]
+		if !ok || !entry.Host() {
+			continue
+		}
+		delete(cached, entry.Version.String())
+		_, _ = fmt.Fprintf(w, "%s\t-\t-\t%s\t-\n", entry.Version, cacheSize(size, ok))
+	}
+	for _, name := range others {
+		_, _ = fmt.Fprintf(w, "%s\t-\t-\t%s\t-\n", name, cacheSize(otherSizes[name], true))
+	}
+	_, _ = fmt.Fprintf(w, "TOTAL\t%s\t%s\t%s\t\n", formatSize(totalApparent), formatSize(totalReal), formatSize(totalCached))
+	_ = w.Flush()
+	Print(buf.String())
+}
+
+// diskUsage 统计版本目录的磁盘占用：apparent 为文件大小之和，freed 为卸载后释放的空间，
+// unique 为未在 seen 中出现过的文件大小之和，系统不支持硬链接信息时三者相同
+func diskUsage(dir string, seen map[uint64]bool) (apparent, freed, unique int64, err error) {
+	err = filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		size := info.Size()
+		apparent += size
+		ino, nlink, ok := path.LinkInfo(info)
+		if !ok {
+			freed += size
+			unique += size
+			return nil
+		}
+		// 去重的文件另有一个链接在 .objects 中，没有其他版本使用时会被一起清理
+		if nlink <= 2 {
+			freed += size
+		}
+		if !seen[ino] {
+			seen[ino] = true
+			unique += size
+		}
+		return nil
+	})
+	return
+}
@@ -4,104 +4,41 @@ package cmd
 
 import (
 	"fmt"
-	"io"
 	"os"
+	"os/signal"
 	"path/filepath"
-	"strings"
+	"syscall"
 
-	"github.com/serious-snow/govm/pkg/utils/path"
+	"github.com/urfave/cli/v3"
 )
 
+// SetEnv 在当前 shell 的 rc 文件中写入环境变量设置
 func SetEnv() {
-	if strings.Contains(os.Getenv("PATH"), envPath) {
-		return
-	}
-	var env string
-	shell := os.Getenv("SHELL")
-	if strings.Contains(shell, "bash") {
-		newEnv := filepath.Join(homeDir, ".bashrc")
-		if path.FileIsExisted(newEnv) {
-			env = newEnv
-		} else {
-			newEnv = filepath.Join(homeDir, ".bash_profile")
-			if path.FileIsExisted(newEnv) {
-				env = newEnv
-			}
-		}
-	} else if strings.Contains(shell, "zsh") {
-		newEnv := filepath.Join(homeDir, ".zshrc")
-		if path.FileIsExisted(newEnv) {
-			env = newEnv
-		}
-	}
-
-	if env == "" {
-		for _, s := range []string{".profile", ".bashrc", ".bash_profile", ".zshrc"} {
-			newEnv := filepath.Join(homeDir, s)
-			if path.FileIsExisted(newEnv) {
-				env = newEnv
-				break
-			}
-		}
-	}
-
-	if env == "" {
-		return
-	}
-
-	file, err := os.OpenFile(env, os.O_CREATE|os.O_APPEND|os.O_RDWR, 0o644)
-	if err != nil {
-		printError("无法打开文件: " + err.Error())
-		return
-	}
-	defer file.Close()
-
-	// 限制读取大小，防止内存溢出
-	const maxFileSize = 10 * 1024 * 1024 // 10MB
-	fileInfo, err := file.Stat()
+	file, err := installEnvBlock(defaultShell())
 	if err != nil {
-		printError("无法获取文件信息: " + err.Error())
-		return
-	}
-	if fileInfo.Size() > maxFileSize {
-		printError("配置文件过大，无法安全读取")
+		printError("设置环境变量失败：" + err.Error())
 		return
 	}
 
-	buf, err := io.ReadAll(file)
-	if err != nil {
-		printError("无法读取文件: " + err.Error())
-		return
-	}
+	printInfo(fmt.Sprintf("\n环境变量设置于 %s\n可能需要重新打开控制台或者注销重新登录才能生效\n", file))
+}
 
-	if strings.Contains(string(buf), envPath) {
-		return
-	}
+// UnsetEnv 从当前 shell 的 rc 文件中删除环境变量设置
+func UnsetEnv() {
+	printUninstallEnvBlock(defaultShell())
+}
 
-	// showSetEnv = os.Setenv("PATH", os.Getenv("PATH")+string(os.PathListSeparator)+linkPath) != nil
-	// Println(os.Getenv("PATH"))
-	_, err = file.WriteString("\nexport PATH=$PATH:")
-	if err != nil {
-		printError("无法写入文件: " + err.Error())
-		return
-	}
-	_, err = file.WriteString(envPath)
-	if err != nil {
-		printError("无法写入文件: " + err.Error())
-		return
-	}
-	_, err = file.WriteString("\n")
-	if err != nil {
-		printError("无法写入文件: " + err.Error())
-		return
-	}
-	err = file.Sync()
-	if err != nil {
-		printError("无法同步文件: " + err.Error())
-		return
+// defaultShell 根据 SHELL 判断当前 shell，无法判断时为 sh
+func defaultShell() string {
+	name := filepath.Base(os.Getenv("SHELL"))
+	switch name {
+	case "bash", "zsh", "fish", "nu", "tcsh", "pwsh":
+		return name
+	case "csh":
+		return "tcsh"
+	default:
+		return "sh"
 	}
-
-	printInfo(fmt.Sprintf("\n环境变量设置于 %s\n可能需要重新打开控制台或者注销重新登录才能生效\n", env))
 }
 
 func Symlink(oldname, newname string) error {
@@ -111,3 +48,40 @@ func Symlink(oldname, newname string) error {
 func replaceExecutable(currentPath, newVersionPath string) error {
 	return os.Rename(currentPath, newVersionPath)
 }
+
+// forwardSignals 将 SIGTERM、SIGHUP 转发给子进程，SIGINT、SIGQUIT 由终端直接发送给子进程，
+// 这里只是忽略，避免 govm 先于子进程退出，返回停止转发的函数
+func forwardSignals(p *os.Process) func() {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGINT, syscall.SIGQUIT, syscall.SIGTERM, syscall.SIGHUP)
+	go func() {
+		for sig := range ch {
+			if sig == syscall.SIGTERM || sig == syscall.SIGHUP {
+				_ = p.Signal(sig)
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(ch)
+		close(ch)
+	}
+}
+
+// exitWithState 以子进程相同的方式退出，子进程被信号终止时，向自身发送相同的信号
+func exitWithState(state *os.ProcessState) error {
+	if ws, ok := state.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+		sig := ws.Signal()
+		signal.Reset(sig)
+		_ = syscall.Kill(os.Getpid(), sig)
+		return cli.Exit("", 128+int(sig))
+	}
+	return cli.Exit("", state.ExitCode())
+}
+
+// userShell 用户的 shell，未设置 SHELL 时使用 /bin/sh
+func userShell() string {
+	if shell := os.Getenv("SHELL"); shell != "" {
+		return shell
+	}
+	return "/bin/sh"
+}
@@ -8,12 +8,14 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/go-ole/go-ole"
 	"github.com/go-ole/go-ole/oleutil"
+	"github.com/urfave/cli/v3"
 	"golang.org/x/sys/windows/registry"
 
 	"github.com/serious-snow/govm/pkg/utils/path"
@@ -64,6 +66,43 @@ func SetEnv() {
 	printInfo("\n设置环境变量成功，可能需要重新打开控制台或者注销重新登录才能生效\n")
 }
 
+// UnsetEnv 从用户环境变量 PATH 中删除 govm 的路径
+func UnsetEnv() {
+	key, err := registry.OpenKey(registry.CURRENT_USER, `Environment`, registry.QUERY_VALUE)
+	if err != nil {
+		ErrorLn("无法打开注册表键:", err)
+		return
+	}
+	defer key.Close()
+
+	oldPath, _, err := key.GetStringValue("PATH")
+	if err != nil {
+		ErrorLn("无法获取环境变量:", err)
+		return
+	}
+
+	newPath := removePathEntry(oldPath, envPath)
+	if newPath == oldPath {
+		Println("环境变量中没有 govm 的路径")
+		return
+	}
+
+	cmd := exec.Command("setx", "PATH", newPath)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		fmt.Println("Failed to run setx command:", err)
+		return
+	}
+
+	printInfo("\n删除环境变量成功，可能需要重新打开控制台或者注销重新登录才能生效\n")
+}
+
+// defaultShell Windows 下默认使用 PowerShell
+func defaultShell() string {
+	return "pwsh"
+}
+
 func Symlink(oldname, newname string) error {
 	err := os.Symlink(oldname, newname)
 	if err == nil {
@@ -171,3 +210,31 @@ del "%%~f0"`, newFile, oldFile, oldFile)
 
 	return nil
 }
+
+// forwardSignals 控制台的 Ctrl+C 会直接发送给子进程，这里只是忽略，
+// 避免 govm 先于子进程退出，返回停止忽略的函数
+func forwardSignals(_ *os.Process) func() {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, os.Interrupt)
+	go func() {
+		for range ch {
+		}
+	}()
+	return func() {
+		signal.Stop(ch)
+		close(ch)
+	}
+}
+
+// exitWithState 以子进程的退出码退出
+func exitWithState(state *os.ProcessState) error {
+	return cli.Exit("", state.ExitCode())
+}
+
+// userShell 用户的 shell，未设置 ComSpec 时使用 cmd.exe
+func userShell() string {
+	if shell := os.Getenv("ComSpec"); shell != "" {
+		return shell
+	}
+	return "cmd.exe"
+}
@@ -1,62 +1,240 @@
 package cmd
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strings"
+	"unicode"
 
 	"github.com/urfave/cli/v3"
+
+	"github.com/serious-snow/govm/pkg/manager"
+	"github.com/serious-snow/govm/pkg/project"
+	"github.com/serious-snow/govm/pkg/version"
 )
 
 func execCommand() *cli.Command {
 	return &cli.Command{
-		Name:      "exec",
-		Aliases:   []string{"e"},
-		Usage:     "Exec command with the PATH pointing to go version",
-		UsageText: getCmdLine("exec", "<version>", "go version"),
+		Name:    "exec",
+		Aliases: []string{"e"},
+		Usage:   "Exec command with the PATH pointing to go version",
+		UsageText: getCmdLine("exec", "[--pure]", "[--version <version>]", "--", "go version") +
+			"\n" + getCmdLine("exec", "<version>", "go version"),
+		Description: "without --version, the version is read from .go-version or go.mod of the current project",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "version",
+				Usage: "go version to use, default from the current project",
+			},
+			&cli.BoolFlag{
+				Name:    "pure",
+				Aliases: []string{"p"},
+				Usage:   "remove other go toolchain environment variables such as GOFLAGS and GOPROXY",
+			},
+		},
+		ShellComplete: completeArgs(1, installedCandidates),
 		Action: func(c *cli.Context) error {
-			if c.NArg() < 2 {
-				return cli.ShowSubcommandHelp(c)
+			args := c.Args().Slice()
+			ver := c.String("version")
+			// 兼容 exec <version> <cmd> 的用法
+			if ver == "" && len(args) >= 2 && looksLikeVersion(args[0]) {
+				ver, args = args[0], args[1:]
 			}
-			version := c.Args().Get(0)
-			if !isInInstall(version) {
-				suggest := suggestVersion(version, ActionExec)
-				if suggest == "" {
-					printError("该版本未安装，请先安装，执行：")
-					printCmdLine("install", version)
-					return nil
-				}
-				version = suggest
+			if len(args) == 0 {
+				return cli.ShowSubcommandHelp(c)
 			}
 
-			goRoot := filepath.Join(conf.InstallPath, version, "go")
-			goBin := filepath.Join(goRoot, "bin")
-			goToolDir := filepath.Join(goRoot, "/pkg/tools/", runtime.GOOS+"_"+runtime.GOARCH)
-			if err := os.Setenv("GOTOOLDIR", goToolDir); err != nil {
-				return err
+			ver = resolveExecVersion(ver, ActionExec)
+			if ver == "" {
+				return nil
 			}
 
-			if err := os.Setenv("GOROOT", goRoot); err != nil {
-				return err
-			}
+			goRoot := mgr.GoRoot(*version.New(ver))
+			return runCommand(goEnv(goRoot, c.Bool("pure")), args)
+		},
+	}
+}
 
-			newPath := goBin + string(os.PathListSeparator) + os.Getenv("PATH")
-			if err := os.Setenv("PATH", newPath); err != nil {
-				return err
-			}
+// resolveExecVersion 解析要使用的已安装版本，ver 为空时从当前目录的项目中读取，
+// 解析失败时输出提示并返回空字符串
+func resolveExecVersion(ver string, action Action) string {
+	if ver == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			printError(err.Error())
+			return ""
+		}
+		projectVersion, file, err := project.Detect(wd)
+		if err != nil {
+			printError(err.Error() + "，请使用 --version 指定")
+			return ""
+		}
+		v, err := mgr.ResolveAtLeast(projectVersion)
+		if err != nil {
+			printError(fmt.Sprintf("%s 要求的版本 %s 未安装，请先安装，执行：", file, projectVersion))
+			printCmdLine("install", projectVersion)
+			return ""
+		}
+		return v.String()
+	}
 
-			args := c.Args().Slice()
-			cmd := exec.Command(args[1], args[2:]...)
-			cmd.Stdin = os.Stdin
-			cmd.Stdout = os.Stdout
-			cmd.Stderr = os.Stderr
+	ver = resolveAlias(manager.TrimVersion(ver), action)
+	if !isInInstall(ver) {
+		suggest := suggestVersion(ver, action)
+		if suggest == "" {
+			printError("该版本未安装，请先安装，执行：")
+			printCmdLine("install", ver)
+			return ""
+		}
+		ver = suggest
+	}
+	return ver
+}
+
+// looksLikeVersion 是否为版本号，如 1.21、go1.21.3
+func looksLikeVersion(s string) bool {
+	s = manager.TrimVersion(s)
+	return s != "" && unicode.IsDigit(rune(s[0])) && version.New(s).Valid()
+}
+
+// goToolchainEnv go 工具链使用的环境变量，见 go help environment，
+// 不包括 GOOGLE_APPLICATION_CREDENTIALS 这样只是以 GO 开头的环境变量
+var goToolchainEnv = map[string]bool{
+	"GO111MODULE": true, "GOARCH": true, "GOAUTH": true, "GOBIN": true, "GOCACHE": true,
+	"GOCACHEPROG": true, "GOCOVERDIR": true, "GODEBUG": true, "GOENV": true, "GOEXPERIMENT": true,
+	"GOFIPS140": true, "GOFLAGS": true, "GOGC": true, "GOINSECURE": true, "GOMAXPROCS": true,
+	"GOMEMLIMIT": true, "GOMODCACHE": true, "GONOPROXY": true, "GONOSUMDB": true, "GOOS": true,
+	"GOPATH": true, "GOPRIVATE": true, "GOPROXY": true, "GOSUMDB": true, "GOTELEMETRY": true,
+	"GOTELEMETRYDIR": true, "GOTMPDIR": true, "GOTRACEBACK": true, "GOVCS": true, "GOWORK": true,
+	"GO386": true, "GOAMD64": true, "GOARM": true, "GOARM64": true, "GOMIPS": true,
+	"GOMIPS64": true, "GOPPC64": true, "GORISCV64": true, "GOWASM": true,
+}
 
-			if err := cmd.Run(); err != nil {
-				return err
+// goEnv 子进程的环境变量：GOROOT、GOTOOLDIR 指向 goRoot，PATH 优先使用 goRoot/bin，
+// GOTOOLCHAIN=local 防止 go 命令切换到其他工具链，pure 为 true 时去除其他 go 工具链的环境变量
+func goEnv(goRoot string, pure bool) []string {
+	goBin := filepath.Join(goRoot, "bin")
+	goToolDir := filepath.Join(goRoot, "pkg", "tool", runtime.GOOS+"_"+runtime.GOARCH)
+
+	// 去除上一层 GOROOT 的 bin 目录，如在 govm shell 中嵌套执行
+	oldGoBin := ""
+	if oldGoRoot := os.Getenv("GOROOT"); oldGoRoot != "" {
+		oldGoBin = filepath.Join(oldGoRoot, "bin")
+	}
+
+	environ := os.Environ()
+	env := make([]string, 0, len(environ)+4)
+	pathValue := ""
+	for _, kv := range environ {
+		k, v, _ := strings.Cut(kv, "=")
+		switch {
+		case envKeyEqual(k, "PATH"):
+			pathValue = removePathEntry(v, oldGoBin)
+			continue
+		case envKeyEqual(k, "GOROOT"), envKeyEqual(k, "GOTOOLDIR"), envKeyEqual(k, "GOTOOLCHAIN"):
+			continue
+		case pure && goToolchainEnv[strings.ToUpper(k)]:
+			continue
+		}
+		env = append(env, kv)
+	}
+
+	if pathValue != "" {
+		goBin += string(os.PathListSeparator) + pathValue
+	}
+	return append(env,
+		"GOROOT="+goRoot,
+		"GOTOOLDIR="+goToolDir,
+		"GOTOOLCHAIN=local",
+		"PATH="+goBin,
+	)
+}
+
+// removePathEntry 从 PATH 中去除 entry
+func removePathEntry(pathValue, entry string) string {
+	if entry == "" {
+		return pathValue
+	}
+	list := filepath.SplitList(pathValue)
+	result := make([]string, 0, len(list))
+	for _, p := range list {
+		if filepath.Clean(p) == filepath.Clean(entry) {
+			continue
+		}
+		result = append(result, p)
+	}
+	return strings.Join(result, string(os.PathListSeparator))
+}
+
+// envKeyEqual Windows 下环境变量名不区分大小写
+func envKeyEqual(a, b string) bool {
+	if isWin {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
+
+func envValue(env []string, key string) string {
+	for i := len(env) - 1; i >= 0; i-- {
+		if k, v, ok := strings.Cut(env[i], "="); ok && envKeyEqual(k, key) {
+			return v
+		}
+	}
+	return ""
+}
+
+// lookPath 在子进程的 PATH 中查找可执行文件，file 包含路径时直接返回
+func lookPath(file, pathValue string) (string, error) {
+	if strings.ContainsAny(file, `/\`) {
+		return file, nil
+	}
+	exts := []string{""}
+	if isWin && filepath.Ext(file) == "" {
+		exts = []string{".exe", ".bat", ".cmd", ".com"}
+	}
+	for _, dir := range filepath.SplitList(pathValue) {
+		for _, ext := range exts {
+			name := filepath.Join(dir, file+ext)
+			info, err := os.Stat(name)
+			if err != nil || info.IsDir() {
+				continue
+			}
+			if isWin || info.Mode()&0o111 != 0 {
+				return name, nil
 			}
+		}
+	}
+	return "", fmt.Errorf("%s: %w", file, exec.ErrNotFound)
+}
 
-			return nil
-		},
+// runCommand 使用 env 运行命令，转发信号，并以子进程的退出码退出
+func runCommand(env []string, args []string) error {
+	name, err := lookPath(args[0], envValue(env, "PATH"))
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command(name, args[1:]...)
+	cmd.Args[0] = args[0]
+	cmd.Env = env
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	stop := forwardSignals(cmd.Process)
+	err = cmd.Wait()
+	stop()
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitWithState(exitErr.ProcessState)
 	}
+	return err
 }
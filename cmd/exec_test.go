@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGoEnvPure(t *testing.T) {
+	t.Setenv("GOFLAGS", "-mod=vendor")
+	t.Setenv("GOPROXY", "direct")
+	t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", "/tmp/key.json")
+
+	env := map[string]string{}
+	for _, kv := range goEnv("/opt/go", true) {
+		k, v, _ := strings.Cut(kv, "=")
+		env[k] = v
+	}
+	if _, ok := env["GOFLAGS"]; ok {
+		t.Error("GOFLAGS should be removed")
+	}
+	if _, ok := env["GOPROXY"]; ok {
+		t.Error("GOPROXY should be removed")
+	}
+	if env["GOOGLE_APPLICATION_CREDENTIALS"] != "/tmp/key.json" {
+		t.Error("GOOGLE_APPLICATION_CREDENTIALS should be kept")
+	}
+	if env["GOROOT"] != "/opt/go" || env["GOTOOLCHAIN"] != "local" {
+		t.Errorf("unexpected GOROOT %q, GOTOOLCHAIN %q", env["GOROOT"], env["GOTOOLCHAIN"])
+	}
+}
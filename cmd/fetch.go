@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"context"
+	"runtime"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/serious-snow/govm/pkg/manager"
+	"github.com/serious-snow/govm/pkg/version"
+)
+
+func fetchCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "fetch",
+		Usage: "Download and verify archives into the cache without installing",
+		UsageText: getCmdLine("fetch", "[--os <os>]", "[--arch <arch>]", "[--jobs <n>]", "[--ignore-sha256]", "<version>...") +
+			"\n" + getCmdLine("fetch", "1.21,1.22", "latest"),
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "os",
+				Usage: "target operating system",
+				Value: runtime.GOOS,
+			},
+			&cli.StringFlag{
+				Name:  "arch",
+				Usage: "target architecture",
+				Value: runtime.GOARCH,
+			},
+			&cli.IntFlag{
+				Name:    "jobs",
+				Aliases: []string{"j"},
+				Usage:   "number of versions downloaded at the same time",
+				Value:   3,
+			},
+			&cli.BoolFlag{
+				Name:    "ignore-sha256",
+				Aliases: []string{"i"},
+				Usage:   "ignore check sha256",
+			},
+		},
+		ShellComplete: completeArgs(0, remoteCandidates),
+		Action: func(c *cli.Context) error {
+			args := splitVersionArgs(c.Args().Slice())
+			if len(args) == 0 {
+				return cli.ShowSubcommandHelp(c)
+			}
+			return fetchVersions(c.Context, args, c.String("os"), c.String("arch"), c.Bool("ignore-sha256"), int(c.Int("jobs")))
+		},
+	}
+}
+
+// fetchVersions 只下载并校验归档文件，版本按本机的版本列表解析，其他平台使用对应平台的版本列表
+func fetchVersions(ctx context.Context, args []string, goos, goarch string, ignore bool, jobs int) error {
+	infos := mgr.Remote()
+	if goos != runtime.GOOS || goarch != runtime.GOARCH {
+		list, err := mgr.RemotePlatform(ctx, goos, goarch)
+		if err != nil {
+			printError("获取 " + goos + "/" + goarch + " 的版本列表失败：" + err.Error())
+			return cli.Exit("", 1)
+		}
+		infos = list
+	}
+	findInfo := func(ver string) *manager.GoVersionInfo {
+		v := version.New(ver)
+		for _, info := range infos {
+			if info.Version.Equal(*v) {
+				return info
+			}
+		}
+		return nil
+	}
+
+	tasks := resolveInstallTasks(args, true)
+	pending := make([]*installTask, 0, len(tasks))
+	for _, task := range tasks {
+		if task.err == nil {
+			pending = append(pending, task)
+		}
+	}
+
+	runTasks(ctx, pending, jobs, func(ctx context.Context, task *installTask) error {
+		info := findInfo(task.version)
+		if info == nil {
+			return manager.ErrNotFound
+		}
+		_, err := mgr.Fetch(ctx, info, manager.FetchOptions{IgnoreSha256: ignore, Quiet: true})
+		return err
+	})
+
+	failed := printTaskSummary("下载结果：", tasks)
+	Println("缓存目录：", conf.CachePath)
+	if failed {
+		return cli.Exit("", 1)
+	}
+	return nil
+}
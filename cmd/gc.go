@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/serious-snow/govm/pkg/history"
+	"github.com/serious-snow/govm/pkg/manager"
+	"github.com/serious-snow/govm/pkg/project"
+	"github.com/serious-snow/govm/pkg/version"
+)
+
+// gcOptions 清理旧版本时的保留条件
+type gcOptions struct {
+	KeepLatestPerMinor bool // 保留每个次版本的最新补丁版本
+	KeepHeld           bool // 保留被保留的版本
+	KeepActive         bool // 保留当前使用的版本
+	KeepReferenced     bool // 保留当前目录的项目和登记的项目要求的版本
+	DryRun             bool
+}
+
+func gcCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "gc",
+		Usage:     "Uninstall old patch versions by a retention policy",
+		UsageText: getCmdLine("gc", "[--keep-latest-per-minor]", "[--keep-held]", "[--keep-active]", "[--keep-referenced]", "[--dry-run]"),
+		Description: "all --keep-* flags are enabled by default, disable them with --keep-*=false,\n" +
+			"the same policy can be applied after upgrade by setting gc.afterUpgrade in the config",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "keep-latest-per-minor",
+				Usage: "keep the latest installed patch of each minor version",
+				Value: true,
+			},
+			&cli.BoolFlag{
+				Name:  "keep-held",
+				Usage: "keep versions on hold",
+				Value: true,
+			},
+			&cli.BoolFlag{
+				Name:  "keep-active",
+				Usage: "keep the current version",
+				Value: true,
+			},
+			&cli.BoolFlag{
+				Name:  "keep-referenced",
+				Usage: "keep versions required by the project in the current directory and registered projects",
+				Value: true,
+			},
+			&cli.BoolFlag{
+				Name:    "dry-run",
+				Aliases: []string{"n"},
+				Usage:   "print versions to uninstall without uninstalling them",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			gc(gcOptions{
+				KeepLatestPerMinor: c.Bool("keep-latest-per-minor"),
+				KeepHeld:           c.Bool("keep-held"),
+				KeepActive:         c.Bool("keep-active"),
+				KeepReferenced:     c.Bool("keep-referenced"),
+				DryRun:             c.Bool("dry-run"),
+			})
+			return nil
+		},
+	}
+}
+
+// gcPlan 按保留条件选出要卸载的已安装版本，从新到旧排序
+func gcPlan(opts gcOptions) []*version.Version {
+	referenced := map[string]string{}
+	if opts.KeepReferenced {
+		referenced = referencedVersions()
+	}
+
+	remove := make([]*version.Version, 0)
+	for _, vs := range manager.GetMinorGroup(localInstallVersions) {
+		// localInstallVersions 从新到旧排序，分组后第一个为最新的补丁版本
+		for i, v := range vs {
+			switch {
+			case opts.KeepLatestPerMinor && i == 0:
+			case opts.KeepHeld && isHold(v.String()):
+			case opts.KeepActive && v.Equal(currentUse):
+			case opts.KeepReferenced && referenced[v.String()] != "":
+			default:
+				remove = append(remove, v)
+			}
+		}
+	}
+	version.SortV(remove).Reverse()
+	return remove
+}
+
+// referencedVersions 当前目录的项目和登记的项目要求的已安装版本，值为声明版本的文件
+func referencedVersions() map[string]string {
+	referenced := map[string]string{}
+	registry := loadProjects()
+	gone := refreshProjects(registry)
+	for _, p := range registry.Projects {
+		if v := projectVersion(p); v != "" && !gone[p] {
+			referenced[v] = p.File
+		}
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return referenced
+	}
+	if ver, file, err := project.Detect(wd); err == nil {
+		if v, err := mgr.ResolveAtLeast(ver); err == nil {
+			referenced[v.String()] = file
+		}
+	}
+	return referenced
+}
+
+func gc(opts gcOptions) {
+	remove := gcPlan(opts)
+	if len(remove) == 0 {
+		Println("没有需要清理的版本")
+		return
+	}
+
+	freed := int64(0)
+	count := 0
+	for _, v := range remove {
+		_, size, _, _ := diskUsage(filepath.Dir(mgr.GoRoot(*v)), map[uint64]bool{})
+		if opts.DryRun {
+			Println("将卸载", v, formatSize(size))
+			freed += size
+			count++
+			continue
+		}
+		if err := mgr.Uninstall(v.String()); err != nil {
+			printError(v.String() + " 卸载失败：" + err.Error())
+			continue
+		}
+		recordHistory(history.ActionUninstall, v.String(), "")
+		Println("已卸载", v, formatSize(size))
+		freed += size
+		count++
+	}
+	// 不保留当前激活的版本时，卸载前已取消激活
+	readCurrentUseVersion()
+	readLocalInstallVersion()
+	readLocalHoldVersion()
+
+	if opts.DryRun {
+		Printf("共 %d 个版本，%s\n", count, formatSize(freed))
+		return
+	}
+	Printf("共卸载 %d 个版本，释放 %s\n", count, formatSize(freed))
+}
+
+// autoGC upgrade 之后按配置的保留策略清理旧版本
+func autoGC() {
+	policy := conf.GC
+	if policy == nil || !policy.AfterUpgrade {
+		return
+	}
+	opts := gcOptions{
+		KeepLatestPerMinor: keepOrDefault(policy.KeepLatestPerMinor),
+		KeepHeld:           keepOrDefault(policy.KeepHeld),
+		KeepActive:         keepOrDefault(policy.KeepActive),
+		KeepReferenced:     keepOrDefault(policy.KeepReferenced),
+	}
+	if len(gcPlan(opts)) == 0 {
+		return
+	}
+	Println("按配置的保留策略清理旧版本：")
+	gc(opts)
+}
+
+// keepOrDefault 保留条件未配置时默认保留
+func keepOrDefault(keep *bool) bool {
+	return keep == nil || *keep
+}
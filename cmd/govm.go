@@ -121,7 +121,7 @@ func upgradeGOVM(ctx context.Context) {
 	fd, fp := filepath.Split(tempFileName)
 
 	Println("下载:", asset.GetBrowserDownloadURL(), "-->", tempFileName)
-	if err := httpc.Download(asset.GetBrowserDownloadURL(), fd, fp, ""); err != nil {
+	if err := httpc.Download(ctx, asset.GetBrowserDownloadURL(), fd, fp, ""); err != nil {
 		Println("govm 下载失败：", err)
 		return
 	}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/serious-snow/govm/pkg/history"
+)
+
+func historyCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "history",
+		Usage:     "Show the history of use, install, uninstall and upgrade",
+		UsageText: getCmdLine("history", "[--limit <n>]"),
+		Flags: []cli.Flag{
+			&cli.IntFlag{
+				Name:    "limit",
+				Aliases: []string{"n"},
+				Usage:   "number of recent entries to show, 0 shows all",
+				Value:   20,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			printHistory(int(c.Int("limit")))
+			return nil
+		},
+	}
+}
+
+// historyFile 操作记录，只追加不修改，存放于 processDir/history.log
+func historyFile() string {
+	return filepath.Join(processDir, "history.log")
+}
+
+// recordHistory 记录一次操作，记录失败不影响操作本身
+func recordHistory(action, from, to string) {
+	err := history.Append(historyFile(), history.Entry{
+		Action: action,
+		From:   from,
+		To:     to,
+	})
+	if err != nil {
+		printError("记录操作历史失败：" + err.Error())
+	}
+}
+
+// previousVersion 上一个激活的版本，用于 use -
+func previousVersion() string {
+	entries, err := history.Read(historyFile())
+	if err != nil {
+		printError("读取操作历史失败：" + err.Error())
+		return ""
+	}
+	current := ""
+	if currentUse.Valid() {
+		current = currentUse.String()
+	}
+	return history.Previous(entries, current)
+}
+
+func printHistory(limit int) {
+	entries, err := history.Read(historyFile())
+	if err != nil {
+		printError("读取操作历史失败：" + err.Error())
+		return
+	}
+	if len(entries) == 0 {
+		Println("没有操作历史")
+		return
+	}
+	if limit > 0 && len(entries) > limit {
+		entries = entries[len(entries)-limit:]
+	}
+
+	orNone := func(v string) string {
+		if v == "" {
+			return "-"
+		}
+		return v
+	}
+	buf := bytes.Buffer{}
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "TIME\tACTION\tFROM\tTO")
+	for _, entry := range entries {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", entry.Time.Local().Format(time.DateTime), entry.Action, orNone(entry.From), orNone(entry.To))
+	}
+	_ = w.Flush()
+	Print(buf.String())
+}
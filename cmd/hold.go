@@ -1,47 +1,158 @@
 package cmd
 
 import (
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
 	"github.com/urfave/cli/v3"
+
+	"github.com/serious-snow/govm/pkg/manager"
+	"github.com/serious-snow/govm/pkg/version"
 )
 
 func holdCommand() *cli.Command {
 	return &cli.Command{
-		Name:      "hold",
-		Usage:     "Place a version on hold",
-		UsageText: getCmdLine("hold", "<version>"),
+		Name:  "hold",
+		Usage: "Place a version on hold",
+		UsageText: getCmdLine("hold", "[--reason <reason>]", "[--expire <30d|2006-01-02>]", "<version|pattern>") +
+			"\n" + getCmdLine("hold", "--list"),
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "list",
+				Aliases: []string{"l"},
+				Usage:   "show hold list",
+			},
+			&cli.StringFlag{
+				Name:    "reason",
+				Aliases: []string{"r"},
+				Usage:   "why the version is held",
+			},
+			&cli.StringFlag{
+				Name:    "author",
+				Aliases: []string{"a"},
+				Usage:   "who holds the version, default current user",
+			},
+			&cli.StringFlag{
+				Name:    "expire",
+				Aliases: []string{"e"},
+				Usage:   "hold expires after a duration (30d, 72h) or at a date (2006-01-02)",
+			},
+		},
+		ShellComplete: completeArgs(1, installedCandidates),
 		Action: func(c *cli.Context) error {
+			if c.Bool("list") {
+				printHolds()
+				return nil
+			}
 			v := c.Args().Get(0)
 			if v == "" {
 				return cli.ShowSubcommandHelp(c)
 			}
-			hold(v)
+
+			opts := manager.HoldOptions{
+				Reason: c.String("reason"),
+				Author: c.String("author"),
+			}
+			if opts.Author == "" {
+				opts.Author = currentUser()
+			}
+			if s := c.String("expire"); s != "" {
+				expiresAt, err := parseExpire(s, time.Now())
+				if err != nil {
+					printError("过期时间格式错误：" + err.Error())
+					return nil
+				}
+				opts.ExpiresAt = &expiresAt
+			}
+			hold(v, opts)
 			return nil
 		},
 	}
 }
 
-func hold(v string) {
-	v = trimVersion(v)
-	if isHold(v) {
+func hold(v string, opts manager.HoldOptions) {
+	if err := mgr.Hold(v, opts); err != nil {
+		if errors.Is(err, manager.ErrNotInstalled) {
+			printError("该版本未安装")
+			return
+		}
+		printError("保存hold版本失败：" + err.Error())
 		return
 	}
-	if !isInInstall(v) {
-		printError("该版本未安装")
+	readLocalHoldVersion()
+}
+
+func printHolds() {
+	if len(holdVersions) == 0 {
+		Println("没有被保留的版本")
 		return
 	}
 
-	holdVersions = append(holdVersions, v)
-
-	if err := saveLocalHoldVersion(); err != nil {
-		printError("保存hold版本失败：" + err.Error())
+	now := time.Now()
+	sb := strings.Builder{}
+	for _, h := range holdVersions {
+		sb.WriteString(fmt.Sprintf("%-10s", h.Pattern))
+		if h.Reason != "" {
+			sb.WriteString("  原因：" + h.Reason)
+		}
+		if h.Author != "" {
+			sb.WriteString("  作者：" + h.Author)
+		}
+		if !h.CreatedAt.IsZero() {
+			sb.WriteString("  创建于：" + h.CreatedAt.Format(time.DateTime))
+		}
+		if h.ExpiresAt != nil {
+			sb.WriteString("  过期于：" + h.ExpiresAt.Format(time.DateTime))
+			if h.Expired(now) {
+				sb.WriteString(color.RedString(" (已过期)"))
+			}
+		}
+		sb.WriteString("\n")
 	}
+	Print(sb.String())
+}
+
+// getHold 版本对应的未过期保留记录，没有时返回 nil
+func getHold(v string) *manager.Hold {
+	return manager.FindHold(holdVersions, *version.New(v))
 }
 
 func isHold(v string) bool {
-	for _, version := range holdVersions {
-		if v == version {
-			return true
-		}
+	return getHold(v) != nil
+}
+
+// holdNote 被保留版本的说明，如：（被 1.21.* 保留：等待迁移）
+func holdNote(v string) string {
+	h := getHold(v)
+	if h == nil {
+		return ""
+	}
+	if h.Reason == "" {
+		return fmt.Sprintf("（被 %s 保留）", h.Pattern)
+	}
+	return fmt.Sprintf("（被 %s 保留：%s）", h.Pattern, h.Reason)
+}
+
+// parseExpire 解析过期时间，支持 30d、72h 这样的时长和 2006-01-02 这样的日期
+func parseExpire(s string, now time.Time) (time.Time, error) {
+	if t, err := time.ParseInLocation(time.DateOnly, s, time.Local); err == nil {
+		return t, nil
+	}
+	d, err := parseDuration(s)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return now.Add(d), nil
+}
+
+func currentUser() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
 	}
-	return false
+	return os.Getenv("USER")
 }
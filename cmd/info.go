@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v3"
+
+	"github.com/serious-snow/govm/pkg/version"
+)
+
+func infoCommand() *cli.Command {
+	return &cli.Command{
+		Name:          "info",
+		Usage:         "Show where an installed version came from and its state",
+		UsageText:     getCmdLine("info", "[<version>]"),
+		Description:   "shows the current version if no <version> is given",
+		ShellComplete: completeArgs(1, installedCandidates),
+		Action: func(c *cli.Context) error {
+			ver := c.Args().Get(0)
+			if ver == "" {
+				if !currentUse.Valid() {
+					return cli.ShowSubcommandHelp(c)
+				}
+				ver = currentUse.String()
+			}
+			v, err := mgr.Resolve(ver, false)
+			if err != nil {
+				printError(err.Error())
+				return cli.Exit("", 1)
+			}
+			printInfoOf(v)
+			return nil
+		},
+	}
+}
+
+func printInfoOf(v version.Version) {
+	goRoot := mgr.GoRoot(v)
+
+	state := "已安装"
+	if v.Equal(currentUse) {
+		state = color.GreenString("当前使用")
+	}
+	hold := "-"
+	if h := getHold(v.String()); h != nil {
+		hold = h.Pattern
+		if h.Reason != "" {
+			hold += "：" + h.Reason
+		}
+	}
+	size := "-"
+	if apparent, freed, _, err := diskUsage(filepath.Dir(goRoot), map[uint64]bool{}); err == nil {
+		size = formatSize(apparent)
+		if freed != apparent {
+			size += "（与其他版本共享，卸载释放 " + formatSize(freed) + "）"
+		}
+	}
+
+	sb := strings.Builder{}
+	row := func(key, value string) {
+		// tabwriter 按字符数对齐，中文占两列，需按显示宽度补齐
+		sb.WriteString(key + ":" + strings.Repeat(" ", 12-displayWidth(key)) + value + "\n")
+	}
+	row("版本", v.String())
+	row("状态", state)
+	row("GOROOT", goRoot)
+	row("磁盘占用", size)
+	row("保留", hold)
+	row("支持状态", mgr.Support(v).String())
+
+	meta := installMetas[v.String()]
+	if meta == nil {
+		row("安装信息", "无，由旧版本 govm 安装")
+		Print(sb.String())
+		return
+	}
+	row("安装时间", meta.InstalledAt.Local().Format(time.DateTime))
+	row("下载地址", meta.URL)
+	sha := meta.Sha256
+	switch {
+	case meta.IgnoreSha256:
+		sha = color.YellowString("未校验（--ignore-sha256）")
+	case sha == "":
+		sha = "-"
+	}
+	row("SHA256", sha)
+	if meta.Slim != nil {
+		row("精简安装", meta.Slim.Name+"，已排除 "+strings.Join(meta.Slim.Exclude, ", "))
+	}
+	row("govm 版本", meta.GovmVersion)
+	Print(sb.String())
+}
+
+// displayWidth 字符串在终端中的显示宽度，中文等宽字符占两列
+func displayWidth(s string) int {
+	width := 0
+	for _, r := range s {
+		if r >= 0x2E80 {
+			width += 2
+			continue
+		}
+		width++
+	}
+	return width
+}
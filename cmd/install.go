@@ -1,25 +1,25 @@
 package cmd
 
 import (
-	"errors"
+	"context"
 	"fmt"
-	"os"
-	"path/filepath"
 
 	"github.com/urfave/cli/v3"
 
-	"github.com/serious-snow/govm/pkg/utils"
-	"github.com/serious-snow/govm/pkg/utils/httpc"
-	"github.com/serious-snow/govm/pkg/utils/path"
-	"github.com/serious-snow/govm/pkg/version"
+	"github.com/serious-snow/govm/pkg/history"
+	"github.com/serious-snow/govm/pkg/manager"
 )
 
 func installCommand() *cli.Command {
 	return &cli.Command{
-		Name:      "install",
-		Aliases:   []string{"i"},
-		Usage:     "Download and install a <version>",
-		UsageText: getCmdLine("install", "[--force]", "[--ignore-sha256]", "<version>"),
+		Name:    "install",
+		Aliases: []string{"i"},
+		Usage:   "Download and install a <version>",
+		UsageText: getCmdLine("install", "[--force]", "[--ignore-sha256]", "[--slim]", "[--slim-profile <name>]", "<version>") +
+			"\n" + getCmdLine("install", "[--jobs <n>]", "<version>", "<version>...") +
+			"\n" + getCmdLine("install", "1.21,1.22,1.23") +
+			"\n" + getCmdLine("install", "'>=1.21,<1.23'") +
+			"\n" + getCmdLine("install", "--for-projects"),
 		Flags: []cli.Flag{
 			&cli.BoolFlag{
 				Name:    "force",
@@ -31,21 +31,71 @@ func installCommand() *cli.Command {
 				Aliases: []string{"i"},
 				Usage:   "ignore check sha256",
 			},
+			&cli.BoolFlag{
+				Name:  "slim",
+				Usage: "skip api, doc, misc, test and testdata, which are not needed to build",
+			},
+			&cli.StringFlag{
+				Name:  "slim-profile",
+				Usage: "slim install with a profile from slimProfiles in the config, implies --slim",
+			},
+			&cli.BoolFlag{
+				Name:  "for-projects",
+				Usage: "install the versions registered projects require but are not installed",
+			},
+			&cli.IntFlag{
+				Name:    "jobs",
+				Aliases: []string{"j"},
+				Usage:   "number of versions downloaded at the same time",
+				Value:   3,
+			},
 		},
+		ShellComplete: completeArgs(0, remoteCandidates),
 		Action: func(c *cli.Context) error {
-			v := c.Args().Get(0)
+			var slim *manager.SlimProfile
+			if c.Bool("slim") || c.String("slim-profile") != "" {
+				profile, err := slimProfile(c.String("slim-profile"))
+				if err != nil {
+					printError(err.Error())
+					return cli.Exit("", 1)
+				}
+				slim = profile
+			}
+
+			args := splitVersionArgs(c.Args().Slice())
+			if c.Bool("for-projects") {
+				missing := projectsMissing()
+				if len(missing) == 0 {
+					Println("登记的项目要求的版本都已安装")
+					return nil
+				}
+				return installVersions(c.Context, append(args, missing...), c.Bool("force"), c.Bool("ignore-sha256"), int(c.Int("jobs")), slim)
+			}
+			if len(args) > 1 || hasConstraint(args) {
+				return installVersions(c.Context, args, c.Bool("force"), c.Bool("ignore-sha256"), int(c.Int("jobs")), slim)
+			}
+
+			v := ""
+			if len(args) == 1 {
+				v = args[0]
+			}
 			if v == "" {
-				return cli.ShowSubcommandHelp(c)
+				if !isInteractive() {
+					return cli.ShowSubcommandHelp(c)
+				}
+				if v = pickVersion("选择要安装的版本", remoteItems()); v == "" {
+					return nil
+				}
 			}
 
-			installVersion(v, c.Bool("force"), c.Bool("ignore-sha256"))
+			installVersion(c.Context, v, c.Bool("force"), c.Bool("ignore-sha256"), slim)
 			return nil
 		},
 	}
 }
 
-func installVersion(version string, force bool, ignore bool) {
-	version = trimVersion(version)
+func installVersion(ctx context.Context, version string, force bool, ignore bool, slim *manager.SlimProfile) {
+	version = resolveAlias(manager.TrimVersion(version), ActionInstall)
 
 	if !force && isInInstall(version) {
 		printError(version + " 已经安装，如需覆盖，请执行：")
@@ -64,11 +114,11 @@ func installVersion(version string, force bool, ignore bool) {
 			printCmdLine("update")
 			return
 		}
-		installVersion(suggest, force, ignore)
+		installVersion(ctx, suggest, force, ignore, slim)
 		return
 	}
 
-	if err := silentInstall(version, true); err != nil {
+	if err := silentInstall(ctx, version, !ignore, slim); err != nil {
 		printError(err.Error())
 		return
 	}
@@ -77,53 +127,32 @@ func installVersion(version string, force bool, ignore bool) {
 	printCmdLine("use", version)
 }
 
-func silentInstall(ver string, checkSha256 bool) error {
-	var versionInfo *GoVersionInfo
-	version := version.New(ver)
-	for _, goVersion := range remoteVersion.Go {
-		if version.Equal(goVersion.Version) {
-			versionInfo = goVersion
-			break
-		}
-	}
-	if versionInfo == nil {
-		return errors.New("暂未找到该版本资源下载")
-	}
-
-	filename := versionInfo.Filename
-
-	oldSha := versionInfo.Sha256
-
-	if !checkSha256 {
-		oldSha = ""
+func silentInstall(ctx context.Context, ver string, checkSha256 bool, slim *manager.SlimProfile) error {
+	err := mgr.Install(ctx, ver, manager.InstallOptions{
+		Force:        true,
+		IgnoreSha256: !checkSha256,
+		Slim:         slim,
+	})
+	if err != nil {
+		return err
 	}
+	readLocalInstallVersion()
+	recordHistory(history.ActionInstall, "", ver)
+	enforceCacheMaxSize()
+	return nil
+}
 
-	newFileName := filepath.Join(conf.CachePath, filename)
-	download := true
-	if path.FileIsExisted(newFileName) {
-		if oldSha == "" || utils.CheckSha256(newFileName, oldSha) {
-			download = false
-		} else {
-			if err := os.Remove(newFileName); err != nil {
-				return fmt.Errorf("删除损坏的缓存文件失败: %w", err)
-			}
-		}
+// slimProfile 精简安装的配置，name 为空时使用 default，配置文件中的同名配置优先
+func slimProfile(name string) (*manager.SlimProfile, error) {
+	if name == "" {
+		name = manager.DefaultSlimProfile.Name
 	}
-	if download {
-		Printf("开始下载：%s\n", version.String())
-		Printf("下载：%s\n", downloadLink+filename)
-		err := httpc.Download(downloadLink+filename, conf.CachePath, filename, oldSha)
-		if err != nil {
-			return err
-		}
+	if exclude, ok := conf.SlimProfiles[name]; ok {
+		return &manager.SlimProfile{Name: name, Exclude: exclude}, nil
 	}
-	// 然后解压到install文件夹
-	toPath := filepath.Join(conf.InstallPath, version.String())
-	err := path.Decompress(newFileName, toPath)
-	if err != nil {
-		_ = os.RemoveAll(toPath)
-		return fmt.Errorf("解压失败:%w", err)
+	if name == manager.DefaultSlimProfile.Name {
+		profile := manager.DefaultSlimProfile
+		return &profile, nil
 	}
-
-	return nil
+	return nil, fmt.Errorf("未找到精简安装配置 %s，请在配置文件的 slimProfiles 中添加", name)
 }
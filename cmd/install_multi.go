@@ -0,0 +1,207 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/cheggaaa/pb/v3"
+	"github.com/urfave/cli/v3"
+
+	"github.com/serious-snow/govm/pkg/history"
+	"github.com/serious-snow/govm/pkg/manager"
+	"github.com/serious-snow/govm/pkg/utils/httpc"
+)
+
+// installTask 同时安装多个版本时的一个版本
+type installTask struct {
+	arg     string // 用户输入的版本
+	version string // 解析后的版本，解析失败时为空
+	skipped bool   // 已安装，跳过
+	err     error
+}
+
+// splitVersionArgs 拆分以逗号分隔的版本列表，如 1.21,1.22，
+// 版本约束中的逗号表示同时满足，如 >=1.21,<1.23，不拆分
+func splitVersionArgs(args []string) []string {
+	result := make([]string, 0, len(args))
+	for _, arg := range args {
+		if manager.IsConstraint(arg) {
+			result = append(result, strings.TrimSpace(arg))
+			continue
+		}
+		for _, v := range strings.Split(arg, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				result = append(result, v)
+			}
+		}
+	}
+	return result
+}
+
+// installVersions 同时安装多个版本，最多 jobs 个版本同时下载，有版本安装失败时以非 0 退出
+func installVersions(ctx context.Context, args []string, force, ignore bool, jobs int, slim *manager.SlimProfile) error {
+	tasks := resolveInstallTasks(args, force)
+	pending := make([]*installTask, 0, len(tasks))
+	for _, task := range tasks {
+		if task.err == nil && !task.skipped {
+			pending = append(pending, task)
+		}
+	}
+
+	if len(pending) != 0 {
+		runTasks(ctx, pending, jobs, func(ctx context.Context, task *installTask) error {
+			return mgr.Install(ctx, task.version, manager.InstallOptions{
+				Force:        true,
+				IgnoreSha256: ignore,
+				Quiet:        true,
+				Slim:         slim,
+			})
+		})
+		readLocalInstallVersion()
+		for _, task := range pending {
+			if task.err == nil {
+				recordHistory(history.ActionInstall, "", task.version)
+			}
+		}
+		enforceCacheMaxSize()
+	}
+
+	if printTaskSummary("安装结果：", tasks) {
+		return cli.Exit("", 1)
+	}
+	return nil
+}
+
+// hasConstraint 版本列表中是否有版本约束
+func hasConstraint(args []string) bool {
+	for _, arg := range args {
+		if manager.IsConstraint(arg) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveInstallTasks 解析要安装的版本，版本约束展开为满足约束的每个次版本的最新稳定版本，去除重复的版本
+func resolveInstallTasks(args []string, force bool) []*installTask {
+	tasks := make([]*installTask, 0, len(args))
+	seen := map[string]bool{}
+	for _, arg := range args {
+		if manager.IsConstraint(arg) {
+			versions, err := mgr.ResolveConstraint(arg)
+			if err != nil {
+				tasks = append(tasks, &installTask{arg: arg, err: err})
+				continue
+			}
+			for _, v := range versions {
+				task := &installTask{arg: arg, version: v.String()}
+				switch {
+				case seen[task.version]:
+					continue
+				case !force && isInInstall(task.version):
+					task.skipped = true
+				}
+				seen[task.version] = true
+				tasks = append(tasks, task)
+			}
+			continue
+		}
+
+		task := &installTask{arg: arg}
+		ver := resolveAlias(manager.TrimVersion(arg), ActionInstall)
+		if !isInLocalCache(ver) {
+			ver = suggestVersion(ver, ActionInstall)
+		}
+		switch {
+		case ver == "":
+			task.err = manager.ErrNotFound
+		case seen[ver]:
+			continue
+		case !force && isInInstall(ver):
+			task.skipped = true
+		}
+		task.version = ver
+		seen[ver] = true
+		tasks = append(tasks, task)
+	}
+	return tasks
+}
+
+// runTasks 使用 jobs 个协程执行 run，每个版本一个进度条，解压由 manager 按版本目录串行执行
+func runTasks(ctx context.Context, tasks []*installTask, jobs int, run func(ctx context.Context, task *installTask) error) {
+	if len(tasks) == 0 {
+		return
+	}
+	if jobs < 1 {
+		jobs = 1
+	}
+
+	bars := make([]*pb.ProgressBar, len(tasks))
+	for i, task := range tasks {
+		bars[i] = httpc.NewBar(0, fmt.Sprintf("%-10s", task.version))
+	}
+	pool := pb.NewPool(bars...)
+	// 不是终端时无法显示进度条，只输出结果
+	if err := pool.Start(); err != nil {
+		pool = nil
+	}
+
+	ch := make(chan int)
+	wg := sync.WaitGroup{}
+	for i := 0; i < jobs && i < len(tasks); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range ch {
+				bar := bars[idx]
+				taskCtx := manager.WithProgress(ctx, func(int64) *pb.ProgressBar {
+					return bar
+				})
+				tasks[idx].err = run(taskCtx, tasks[idx])
+				// 结束后进度条只显示结果，没有下载时（如使用了缓存）也能看到状态
+				status := "完成"
+				if tasks[idx].err != nil {
+					status = "失败"
+				}
+				bar.SetTemplateString(`{{ string . "prefix" }}` + status)
+				bar.Finish()
+			}
+		}()
+	}
+	for i := range tasks {
+		ch <- i
+	}
+	close(ch)
+	wg.Wait()
+
+	if pool != nil {
+		_ = pool.Stop()
+	}
+}
+
+// printTaskSummary 输出每个版本的结果，有失败时返回 true
+func printTaskSummary(title string, tasks []*installTask) bool {
+	success, skipped, failed := 0, 0, 0
+	Println(title)
+	for _, task := range tasks {
+		name := task.version
+		if name == "" {
+			name = task.arg
+		}
+		switch {
+		case task.err != nil:
+			failed++
+			printError(fmt.Sprintf("  %-10s 失败：%s", name, task.err))
+		case task.skipped:
+			skipped++
+			Printf("  %-10s 已经安装，跳过\n", name)
+		default:
+			success++
+			printInfo(fmt.Sprintf("  %-10s 成功", name))
+		}
+	}
+	Printf("共 %d 个版本，成功 %d 个，跳过 %d 个，失败 %d 个\n", len(tasks), success, skipped, failed)
+	return failed != 0
+}
@@ -1,26 +1,29 @@
 package cmd
 
 import (
-	"encoding/json"
-	"runtime"
-	"sort"
+	"bytes"
+	"context"
+	"fmt"
 	"strings"
+	"text/tabwriter"
 	"time"
 
 	"github.com/briandowns/spinner"
 	"github.com/fatih/color"
 	"github.com/urfave/cli/v3"
 
-	"github.com/serious-snow/govm/pkg/utils/httpc"
+	"github.com/serious-snow/govm/pkg/manager"
+	"github.com/serious-snow/govm/pkg/notes"
 	"github.com/serious-snow/govm/pkg/version"
 )
 
 func listCommand() *cli.Command {
 	return &cli.Command{
-		Name:      "list",
-		Aliases:   []string{"l"},
-		Usage:     "Show version list",
-		UsageText: getCmdLine("list", "[--installed]"),
+		Name:    "list",
+		Aliases: []string{"l"},
+		Usage:   "Show version list",
+		UsageText: getCmdLine("list", "[--installed]", "[--minor <1.22>]", "[--stable|--prerelease]", "[--cached]", "[--since <1.20>]", "[--limit <n>]", "[--wide]") +
+			"\n" + getCmdLine("list", "--upgradeable"),
 		Flags: []cli.Flag{
 			&cli.BoolFlag{
 				Name:    "installed",
@@ -32,31 +35,106 @@ func listCommand() *cli.Command {
 				Aliases: []string{"u"},
 				Usage:   "show upgradeable version list",
 			},
+			&cli.BoolFlag{
+				Name:  "notes",
+				Usage: "show release notes of the skipped patches, works with --upgradeable",
+			},
+			&cli.StringFlag{
+				Name:  "minor",
+				Usage: "only show versions of the minor version, such as 1.22",
+			},
+			&cli.BoolFlag{
+				Name:    "stable",
+				Aliases: []string{"s"},
+				Usage:   "only show stable versions",
+			},
+			&cli.BoolFlag{
+				Name:    "prerelease",
+				Aliases: []string{"p"},
+				Usage:   "only show rc and beta versions",
+			},
+			&cli.BoolFlag{
+				Name:    "cached",
+				Aliases: []string{"c"},
+				Usage:   "only show versions whose archive is cached",
+			},
+			&cli.StringFlag{
+				Name:  "since",
+				Usage: "only show versions since the version, such as 1.20",
+			},
+			&cli.IntFlag{
+				Name:    "limit",
+				Aliases: []string{"n"},
+				Usage:   "show at most n versions",
+			},
+			&cli.BoolFlag{
+				Name:    "wide",
+				Aliases: []string{"w"},
+				Usage:   "show size, cached, hold, support, release date and path columns",
+			},
 		},
 
 		Action: func(c *cli.Context) error {
 			if len(remoteVersion.Go) == 0 {
-				reloadAvailable()
+				reloadAvailable(c.Context)
 			}
 
+			filter := versionFilter{
+				Stable:     c.Bool("stable"),
+				Prerelease: c.Bool("prerelease"),
+				Cached:     c.Bool("cached"),
+				Limit:      int(c.Int("limit")),
+			}
+			// 1.22.3、go1.22rc1 都按次版本号 1.22 过滤
+			if minor := manager.TrimVersion(c.String("minor")); minor != "" {
+				v := version.New(minor)
+				if v.Major == 0 && v.Minor == 0 {
+					printError("无效的次版本号 " + c.String("minor") + "，如：1.22")
+					return cli.Exit("", 1)
+				}
+				filter.Minor = v.MinorVersion()
+			}
+			if since := manager.TrimVersion(c.String("since")); since != "" {
+				v := version.New(since)
+				if !v.Valid() {
+					printError("无效的版本号 " + c.String("since") + "，如：1.20")
+					return cli.Exit("", 1)
+				}
+				filter.Since = v
+			}
+			if filter.Stable && filter.Prerelease {
+				printError("--stable 和 --prerelease 不能同时使用")
+				return cli.Exit("", 1)
+			}
+
+			var versions []*version.Version
 			switch {
-			case c.Bool("installed"):
-				printInstalled()
 			case c.Bool("upgradeable"):
-				printUpgradeable()
+				printUpgradeable(c.Bool("notes"))
+				return nil
+			case c.Bool("installed"):
+				versions = localInstallVersions
 			default:
-				printAvailable()
+				versions = availableVersions()
+			}
+
+			versions = filter.Apply(versions)
+			if c.Bool("wide") {
+				printVersionTable(versions)
+			} else {
+				printVersions(versions)
 			}
 			return nil
 		},
 	}
 }
 
-func reloadAvailable() {
+func reloadAvailable(ctx context.Context) {
 	Println("正在拉取 go 最新版本列表...")
 	spin := spinner.New(spinner.CharSets[14], time.Millisecond*100)
 	spin.Start()
-	res, err := getAvailable()
+	oldCount := len(remoteVersion.Go)
+	res, err := mgr.Refresh(ctx)
 	if err != nil {
 		spin.Stop()
 		printError("列表更新失败：" + err.Error())
@@ -65,7 +143,7 @@ func reloadAvailable() {
 	spin.Stop()
 
 	if len(localInstallVersions) != 0 {
-		Println("列表更新完成, 本次更新 新增数量为:", len(res)-len(remoteVersion.Go))
+		Println("列表更新完成, 本次更新 新增数量为:", len(res)-oldCount)
 	}
 
 	remoteVersion.Go = res
@@ -74,77 +152,115 @@ func reloadAvailable() {
 	}
 }
 
-func getAvailable() ([]*GoVersionInfo, error) {
-	// https://go.dev/dl/?mode=json&include=all
-	link := downloadLink + "?mode=json&include=all"
-
-	var result []*ListGoVersionResponse
-	buf, err := httpc.Get(link)
-	if err != nil {
-		return nil, err
-	}
-	if err = json.Unmarshal(buf, &result); err != nil {
-		return nil, err
+func availableVersions() []*version.Version {
+	versions := make([]*version.Version, 0, len(remoteVersion.Go))
+	for _, v := range remoteVersion.Go {
+		versions = append(versions, &v.Version)
 	}
-	list := make([]*GoVersionInfo, 0, len(result))
+	return versions
+}
 
-	seen := map[string]struct{}{}
-	for _, response := range result {
-		for _, file := range response.Files {
+// versionFilter list 的过滤条件，零值不过滤
+type versionFilter struct {
+	Minor      string // 次版本，如 1.22
+	Stable     bool   // 只显示稳定版本
+	Prerelease bool   // 只显示非稳定版本
+	Cached     bool
+	Since      *version.Version
+	Limit      int
+}
 
-			vv := trimVersion(file.Version)
-			if file.Kind != "archive" {
-				continue
-			}
-			if file.Os != runtime.GOOS || file.Arch != runtime.GOARCH {
-				continue
-			}
-			if _, ok := seen[vv]; ok {
-				continue
-			}
-			seen[vv] = struct{}{}
+func (f versionFilter) Match(v version.Version) bool {
+	prerelease := !mgr.IsStable(v)
+	switch {
+	case f.Minor != "" && v.MinorVersion() != f.Minor:
+		return false
+	case f.Stable && prerelease, f.Prerelease && !prerelease:
+		return false
+	case f.Cached && !mgr.IsCached(v):
+		return false
+	case f.Since != nil && v.Less(*f.Since):
+		return false
+	}
+	return true
+}
 
-			v := version.New(vv)
-			if v == nil {
-				continue
-			}
-			list = append(list, &GoVersionInfo{
-				Filename: file.Filename,
-				Sha256:   file.Sha256,
-				Size:     file.Size,
-				Version:  *v,
-			})
+// Apply 按顺序过滤，最多返回 Limit 个版本
+func (f versionFilter) Apply(vs []*version.Version) []*version.Version {
+	result := make([]*version.Version, 0, len(vs))
+	for _, v := range vs {
+		if f.Limit > 0 && len(result) >= f.Limit {
+			break
+		}
+		if f.Match(*v) {
+			result = append(result, v)
 		}
 	}
-
-	sort.Slice(list, func(i, j int) bool {
-		return list[i].Version.Greater(list[j].Version)
-	})
-
-	return list, nil
+	return result
 }
 
-func printAvailable() {
-	versions := make([]*version.Version, 0, len(remoteVersion.Go))
-	for _, v := range remoteVersion.Go {
-		versions = append(versions, &v.Version)
+// printVersionTable 分列显示版本的大小、缓存、保留、维护状态、发布日期、安装日期和安装路径
+func printVersionTable(vs []*version.Version) {
+	buf := bytes.Buffer{}
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "  \tVERSION\tSIZE\tCACHED\tHOLD\tSUPPORT\tRELEASED\tINSTALLED\tPATH")
+	for _, v := range vs {
+		active, size, cached, hold, released, installed, goRoot := "", "-", "-", "-", "-", "-", "-"
+		if v.Equal(currentUse) {
+			active = "->"
+		}
+		if info := mgr.RemoteInfo(*v); info != nil {
+			size = formatSize(int64(info.Size))
+			if info.ReleasedAt != nil {
+				released = info.ReleasedAt.Local().Format(time.DateOnly)
+			}
+		}
+		if mgr.IsCached(*v) {
+			cached = "yes"
+		}
+		if isHold(v.String()) {
+			hold = "yes"
+		}
+		if isInstall(*v) {
+			goRoot = mgr.GoRoot(*v)
+		}
+		if meta := installMetas[v.String()]; meta != nil {
+			installed = meta.InstalledAt.Local().Format(time.DateOnly)
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", active, v, size, cached, hold, mgr.Support(*v), released, installed, goRoot)
 	}
-	printVersions(versions)
-}
+	_ = w.Flush()
 
-func printInstalled() {
-	printVersions(localInstallVersions)
+	// 对齐之后再上色，颜色与 printVersions 一致
+	lines := strings.SplitAfter(buf.String(), "\n")
+	sb := strings.Builder{}
+	sb.WriteString(lines[0])
+	for i, v := range vs {
+		switch {
+		case v.Equal(currentUse):
+			_, _ = color.New(color.FgGreen).Fprint(&sb, lines[i+1])
+		case isInstall(*v):
+			_, _ = color.New(color.FgBlue).Fprint(&sb, lines[i+1])
+		default:
+			sb.WriteString(lines[i+1])
+		}
+	}
+	Print(sb.String())
 }
 
 func printVersions(vs []*version.Version) {
 	sb := strings.Builder{}
-	using, other, holding := "->     ", "       ", " (hold)"
+	using, other, holding, eol := "->     ", "       ", " (hold)", " (EOL)"
 	for _, v := range vs {
 		if isInstall(*v) {
 			holdStr := ""
 			if isHold(v.String()) {
 				holdStr = holding
 			}
+			// 已安装但停止维护的版本需要提示
+			if mgr.Support(*v) == manager.EOL {
+				holdStr += eol
+			}
 			if version.Equal(*v, currentUse) {
 				_, _ = color.New(color.FgGreen).Fprint(&sb, using, v.String(), holdStr)
 			} else {
@@ -161,13 +277,22 @@ func printVersions(vs []*version.Version) {
 	sb.Reset()
 }
 
-func printUpgradeable() {
-	m := getUpgradeableList()
+func printUpgradeable(withNotes bool) {
+	m := mgr.Upgradeable()
 	if len(m) == 0 {
 		println("所有版本均是最新")
 		return
 	}
 
+	var noteList []*notes.Note
+	if withNotes {
+		latest := make([]*version.Version, 0, len(m))
+		for s := range m {
+			latest = append(latest, version.New(s))
+		}
+		noteList = loadNotes(newestVersion(latest), false)
+	}
+
 	sb := strings.Builder{}
 	count := 0
 	sbHold := strings.Builder{}
@@ -179,6 +304,8 @@ func printUpgradeable() {
 				sbHold.WriteString(v.String())
 				sbHold.WriteString(" -> ")
 				sbHold.WriteString(s)
+				sbHold.WriteString(" ")
+				sbHold.WriteString(holdNote(v.String()))
 				sbHold.WriteString("\n")
 				continue
 			}
@@ -187,6 +314,7 @@ func printUpgradeable() {
 			sb.WriteString(" -> ")
 			sb.WriteString(s)
 			sb.WriteString("\n")
+			writeUpgradeNotes(&sb, noteList, *v, *version.New(s))
 		}
 	}
 
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/briandowns/spinner"
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v3"
+
+	"github.com/serious-snow/govm/pkg/manager"
+	"github.com/serious-snow/govm/pkg/notes"
+	"github.com/serious-snow/govm/pkg/version"
+)
+
+func notesCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "notes",
+		Usage: "Show release notes of a version or range",
+		UsageText: getCmdLine("notes", "[--refresh]", "<version>") +
+			"\n" + getCmdLine("notes", "[--refresh]", "<from>..<to>"),
+		Description: "<version> can be a patch version such as 1.21.5 or a minor version such as 1.21,\n" +
+			"<from>..<to> shows the versions after <from> up to and including <to>,\n" +
+			"the notes are cached and can be read offline",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "refresh",
+				Aliases: []string{"r"},
+				Usage:   "fetch the release notes again",
+			},
+		},
+		ShellComplete: completeArgs(1, installedCandidates),
+		Action: func(c *cli.Context) error {
+			arg := c.Args().Get(0)
+			if arg == "" {
+				return cli.ShowSubcommandHelp(c)
+			}
+
+			from, to, isRange := strings.Cut(arg, "..")
+			newest := version.New(manager.TrimVersion(from))
+			if isRange {
+				newest = version.New(manager.TrimVersion(to))
+			}
+			list := loadNotes(newest, c.Bool("refresh"))
+
+			var result []*notes.Note
+			switch v := version.New(manager.TrimVersion(from)); {
+			case isRange:
+				result = notes.Between(list, *v, *newest)
+			case v.Patch == nil && !v.RC && !v.Beta:
+				result = notes.Minor(list, v.MinorVersion())
+			default:
+				if note := notes.Find(list, *v); note != nil {
+					result = append(result, note)
+				}
+			}
+
+			if len(result) == 0 {
+				printError("未找到 " + arg + " 的发布说明")
+				return nil
+			}
+			printNotes(result, "")
+			return nil
+		},
+	}
+}
+
+// loadNotes 读取缓存的发布说明，缓存为空、refresh 为 true 或缓存中没有 need 版本时重新获取，
+// 获取失败时使用缓存
+func loadNotes(need *version.Version, refresh bool) []*notes.Note {
+	file := filepath.Join(conf.CachePath, "notes.json")
+	list, err := notes.Load(file)
+	if err != nil {
+		printError("读取发布说明缓存失败：" + err.Error())
+	}
+
+	if !refresh && len(list) != 0 && (need == nil || !need.Greater(list[0].Version)) {
+		return list
+	}
+
+	spin := spinner.New(spinner.CharSets[14], time.Millisecond*100)
+	spin.Suffix = " 正在获取发布说明..."
+	spin.Start()
+	fetched, err := notes.Fetch()
+	spin.Stop()
+	if err != nil {
+		printError("获取发布说明失败：" + err.Error())
+		return list
+	}
+	if err := notes.Save(file, fetched); err != nil {
+		printError("保存发布说明失败：" + err.Error())
+	}
+	return fetched
+}
+
+// printNotes 输出发布说明，indent 为每行的缩进
+func printNotes(list []*notes.Note, indent string) {
+	sb := strings.Builder{}
+	writeNotes(&sb, list, indent)
+	Print(sb.String())
+}
+
+func writeNotes(sb *strings.Builder, list []*notes.Note, indent string) {
+	for _, note := range list {
+		sb.WriteString(indent)
+		_, _ = color.New(color.FgCyan).Fprint(sb, note.Version.String())
+		if note.Date != "" {
+			sb.WriteString(" (" + note.Date + ")")
+		}
+		sb.WriteString("\n")
+		sb.WriteString(indent + "  " + note.Text + "\n")
+	}
+}
+
+// writeUpgradeNotes 写入从 from 升级到 to 跳过的版本的发布说明，list 为空时不写入
+func writeUpgradeNotes(sb *strings.Builder, list []*notes.Note, from, to version.Version) {
+	writeNotes(sb, notes.Between(list, from, to), "    ")
+}
+
+// newestVersion 版本列表中最新的版本，用于判断发布说明缓存是否需要更新
+func newestVersion(vs []*version.Version) *version.Version {
+	var newest *version.Version
+	for _, v := range vs {
+		if newest == nil || v.Greater(*newest) {
+			newest = v
+		}
+	}
+	return newest
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/serious-snow/govm/pkg/manager"
+	"github.com/serious-snow/govm/pkg/project"
+	"github.com/serious-snow/govm/pkg/version"
+)
+
+func outdatedCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "outdated",
+		Usage:     "Check whether the go version of the current project is EOL or outdated",
+		UsageText: getCmdLine("outdated", "[--exit-code]"),
+		Description: "the version is read from .go-version or go.mod of the current project,\n" +
+			"the go directive of go.mod is a minimum version and is only checked for EOL",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "exit-code",
+				Aliases: []string{"e"},
+				Usage:   "exit with 1 when the version is EOL or a pinned version is behind the latest patch, 2 on error",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			if len(remoteVersion.Go) == 0 {
+				reloadAvailable(c.Context)
+			}
+
+			outdated, err := checkOutdated()
+			if err != nil {
+				printError(err.Error())
+				if c.Bool("exit-code") {
+					return cli.Exit("", 2)
+				}
+				return nil
+			}
+			if outdated && c.Bool("exit-code") {
+				return cli.Exit("", 1)
+			}
+			return nil
+		},
+	}
+}
+
+// checkOutdated 检查当前项目要求的版本是否停止维护或落后于最新补丁版本
+func checkOutdated() (bool, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return false, err
+	}
+	ver, file, err := project.Detect(wd)
+	if err != nil {
+		return false, err
+	}
+	if len(remoteVersion.Go) == 0 {
+		return false, fmt.Errorf("版本列表为空，请执行：%s", getCmdLine("update"))
+	}
+
+	v := version.New(manager.TrimVersion(ver))
+	Printf("%s 要求的版本：%s\n", file, v)
+
+	outdated := false
+	if mgr.Support(*v) == manager.EOL {
+		outdated = true
+		printError(fmt.Sprintf("%s 已停止维护，仍在维护的版本：%s", v, strings.Join(mgr.SupportedMinors(), ", ")))
+	}
+	// go.mod 中的 go 指令只是最低版本，只检查是否停止维护
+	pinned := project.Pinned(file)
+	if latest, behind := mgr.IsBehind(*v); pinned && behind {
+		outdated = true
+		printError(fmt.Sprintf("%s 不是最新的补丁版本，最新为 %s", v, latest))
+	}
+	switch {
+	case outdated:
+	case pinned:
+		printInfo(v.String() + " 仍在维护且是最新的补丁版本")
+	default:
+		printInfo(v.String() + " 仍在维护")
+	}
+	return outdated, nil
+}
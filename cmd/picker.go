@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"strings"
+
+	"github.com/manifoldco/promptui"
+	"golang.org/x/term"
+
+	"github.com/serious-snow/govm/pkg/version"
+)
+
+// versionItem 选择器中的一个版本
+type versionItem struct {
+	Version string
+	Note    string
+}
+
+// isInteractive 标准输入输出都是终端时才能使用选择器
+func isInteractive() bool {
+	return term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// pickVersion 显示可搜索的版本选择器，没有可选版本或取消时返回空字符串
+func pickVersion(label string, items []versionItem) string {
+	if len(items) == 0 {
+		Println("没有可选择的版本")
+		return ""
+	}
+
+	prompt := promptui.Select{
+		Label: label,
+		Items: items,
+		Size:  10,
+		Templates: &promptui.SelectTemplates{
+			Label:    "{{ . }}",
+			Active:   promptui.IconSelect + " {{ .Version | cyan }} {{ .Note | faint }}",
+			Inactive: "  {{ .Version }} {{ .Note | faint }}",
+			Selected: promptui.IconGood + " {{ .Version }}",
+		},
+		Searcher: func(input string, index int) bool {
+			return strings.Contains(items[index].Version, strings.TrimSpace(input))
+		},
+	}
+	i, _, err := prompt.Run()
+	if err != nil {
+		if errors.Is(err, promptui.ErrInterrupt) {
+			os.Exit(130)
+		}
+		return ""
+	}
+	return items[i].Version
+}
+
+// versionNote 版本的标记：当前使用、已安装、保留、已缓存、可升级以及安装包大小
+func versionNote(v version.Version, markInstalled bool) string {
+	notes := make([]string, 0, 5)
+	if v.Equal(currentUse) {
+		notes = append(notes, "当前使用")
+	}
+	if markInstalled && isInstall(v) {
+		notes = append(notes, "已安装")
+	}
+	if isHold(v.String()) {
+		notes = append(notes, "保留")
+	}
+	if mgr.IsCached(v) {
+		notes = append(notes, "已缓存")
+	}
+	if isInstall(v) {
+		if latest := mgr.PatchNewest(v); latest != nil && latest.Compare(v) > 0 {
+			notes = append(notes, "可升级到 "+latest.String())
+		}
+	}
+	if info := mgr.RemoteInfo(v); info != nil && info.Size > 0 {
+		notes = append(notes, formatSize(int64(info.Size)))
+	}
+	if len(notes) == 0 {
+		return ""
+	}
+	return "(" + strings.Join(notes, ", ") + ")"
+}
+
+// installedItems 已安装的版本
+func installedItems() []versionItem {
+	items := make([]versionItem, 0, len(localInstallVersions))
+	for _, v := range localInstallVersions {
+		items = append(items, versionItem{Version: v.String(), Note: versionNote(*v, false)})
+	}
+	return items
+}
+
+// remoteItems 本地缓存的远程版本列表
+func remoteItems() []versionItem {
+	items := make([]versionItem, 0, len(remoteVersion.Go))
+	for _, info := range remoteVersion.Go {
+		items = append(items, versionItem{Version: info.Version.String(), Note: versionNote(info.Version, true)})
+	}
+	return items
+}
@@ -0,0 +1,212 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v3"
+
+	"github.com/serious-snow/govm/pkg/project"
+)
+
+func projectsCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "projects",
+		Usage:     "Register projects and the go versions they require",
+		UsageText: getCmdLine("projects", "[add]", "[scan]", "[list]", "[rm]"),
+		Description: "uninstall and gc keep versions required by registered projects,\n" +
+			"install --for-projects installs the versions they need",
+		Commands: []*cli.Command{
+			{
+				Name:      "add",
+				Usage:     "Register the projects of directories",
+				UsageText: getCmdLine("projects", "add", "<dir>..."),
+				Action: func(c *cli.Context) error {
+					if c.NArg() == 0 {
+						return cli.ShowSubcommandHelp(c)
+					}
+					addProjects(c.Args().Slice())
+					return nil
+				},
+			},
+			{
+				Name:      "scan",
+				Usage:     "Register all projects under a directory",
+				UsageText: getCmdLine("projects", "scan", "<dir>"),
+				Action: func(c *cli.Context) error {
+					dir := c.Args().Get(0)
+					if dir == "" {
+						return cli.ShowSubcommandHelp(c)
+					}
+					dirs, err := project.Scan(dir)
+					if err != nil {
+						printError("扫描失败：" + err.Error())
+					}
+					if len(dirs) == 0 {
+						Println(dir, "下没有找到声明 go 版本的项目")
+						return nil
+					}
+					addProjects(dirs)
+					return nil
+				},
+			},
+			{
+				Name:    "list",
+				Aliases: []string{"ls"},
+				Usage:   "List registered projects and whether the versions they require are installed",
+				Action: func(c *cli.Context) error {
+					printProjects()
+					return nil
+				},
+			},
+			{
+				Name:      "rm",
+				Usage:     "Unregister projects",
+				UsageText: getCmdLine("projects", "rm", "<dir>..."),
+				Action: func(c *cli.Context) error {
+					if c.NArg() == 0 {
+						return cli.ShowSubcommandHelp(c)
+					}
+					registry := loadProjects()
+					for _, dir := range c.Args().Slice() {
+						if registry.Remove(dir) {
+							Println("已取消登记", dir)
+						} else {
+							printError(dir + " 未登记")
+						}
+					}
+					saveProjects(registry)
+					return nil
+				},
+			},
+		},
+	}
+}
+
+// loadProjects 读取项目登记表，登记表存放于 processDir/projects.json
+func loadProjects() *project.Registry {
+	registry, err := project.LoadRegistry(filepath.Join(processDir, "projects.json"))
+	if err != nil {
+		printError("读取项目登记表失败：" + err.Error())
+	}
+	return registry
+}
+
+func saveProjects(registry *project.Registry) {
+	if err := registry.Save(); err != nil {
+		printError("保存项目登记表失败：" + err.Error())
+	}
+}
+
+func addProjects(dirs []string) {
+	registry := loadProjects()
+	for _, dir := range dirs {
+		p, err := registry.Add(dir)
+		if err != nil {
+			printError(dir + " 登记失败：" + err.Error())
+			continue
+		}
+		Printf("已登记 %s，要求的版本：%s\n", p.Dir, p.Version)
+	}
+	saveProjects(registry)
+}
+
+// refreshProjects 重新读取项目要求的版本，返回已不存在或不再声明版本的项目
+func refreshProjects(registry *project.Registry) map[*project.Project]bool {
+	gone := map[*project.Project]bool{}
+	for _, p := range registry.Refresh() {
+		gone[p] = true
+	}
+	return gone
+}
+
+// projectVersion 项目要求的版本对应的已安装版本，未安装时返回空
+func projectVersion(p *project.Project) string {
+	v, err := mgr.ResolveAtLeast(p.Version)
+	if err != nil {
+		return ""
+	}
+	return v.String()
+}
+
+func printProjects() {
+	registry := loadProjects()
+	if len(registry.Projects) == 0 {
+		Println("没有登记的项目，请执行：")
+		printCmdLine("projects", "scan", "<dir>")
+		return
+	}
+	gone := refreshProjects(registry)
+	saveProjects(registry)
+
+	buf := bytes.Buffer{}
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "DIR\tREQUIRES\tINSTALLED\tFILE")
+	missing := 0
+	states := make([]string, 0, len(registry.Projects))
+	for _, p := range registry.Projects {
+		installed := projectVersion(p)
+		state := ""
+		switch {
+		case gone[p]:
+			installed, state = "gone", "gone"
+		case installed == "":
+			installed, state = "missing", "missing"
+			missing++
+		}
+		states = append(states, state)
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", p.Dir, p.Version, installed, filepath.Base(p.File))
+	}
+	_ = w.Flush()
+
+	// 对齐之后再上色，颜色由登记项的状态决定，不从输出的内容判断
+	lines := strings.SplitAfter(buf.String(), "\n")
+	sb := strings.Builder{}
+	sb.WriteString(lines[0])
+	for i, state := range states {
+		switch state {
+		case "missing":
+			_, _ = color.New(color.FgRed).Fprint(&sb, lines[i+1])
+		case "gone":
+			_, _ = color.New(color.Faint).Fprint(&sb, lines[i+1])
+		default:
+			sb.WriteString(lines[i+1])
+		}
+	}
+	Print(sb.String())
+
+	if missing != 0 {
+		Printf("%d 个项目要求的版本未安装，请执行：\n", missing)
+		printCmdLine("install", "--for-projects")
+	}
+	if len(gone) != 0 {
+		Printf("%d 个项目已不存在或不再声明 go 版本，可执行 %s 取消登记\n", len(gone), getCmdLine("projects", "rm", "<dir>"))
+	}
+}
+
+// projectsMissing 登记的项目要求但未安装的版本，按远程列表解析为具体版本
+func projectsMissing() []string {
+	registry := loadProjects()
+	gone := refreshProjects(registry)
+	seen := map[string]bool{}
+	result := make([]string, 0)
+	for _, p := range registry.Projects {
+		if gone[p] || projectVersion(p) != "" {
+			continue
+		}
+		v, err := mgr.Resolve(p.Version, true)
+		if err != nil {
+			printError(p.Dir + " 要求的版本 " + p.Version + " " + err.Error())
+			continue
+		}
+		if !seen[v.String()] {
+			seen[v.String()] = true
+			result = append(result, v.String())
+		}
+	}
+	return result
+}
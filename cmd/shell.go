@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/serious-snow/govm/pkg/version"
+)
+
+const (
+	shellVersionEnv = "GOVM_SHELL_VERSION" // 当前 govm shell 绑定的版本，可用于显示在提示符中
+	shellLevelEnv   = "GOVM_SHELL_LEVEL"   // govm shell 的嵌套层数
+)
+
+func shellCommand() *cli.Command {
+	return &cli.Command{
+		Name:        "shell",
+		Aliases:     []string{"sh"},
+		Usage:       "Start a subshell bound to a <version>",
+		UsageText:   getCmdLine("shell", "[--pure]", "[version]"),
+		Description: "without version, the version is read from .go-version or go.mod of the current project, exit to return",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "pure",
+				Aliases: []string{"p"},
+				Usage:   "remove other GO* environment variables",
+			},
+		},
+		ShellComplete: completeArgs(1, useCandidates),
+		Action: func(c *cli.Context) error {
+			ver := resolveExecVersion(c.Args().Get(0), ActionExec)
+			if ver == "" {
+				return nil
+			}
+
+			level, _ := strconv.Atoi(os.Getenv(shellLevelEnv))
+			if current := os.Getenv(shellVersionEnv); current != "" {
+				if version.New(current).Equal(*version.New(ver)) {
+					printInfo(fmt.Sprintf("已在 go %s 的 shell 中", ver))
+					return nil
+				}
+				printInfo(fmt.Sprintf("当前已在 go %s 的 shell 中（第 %d 层），将嵌套启动新的 shell", current, level))
+			}
+
+			env := append(goEnv(mgr.GoRoot(*version.New(ver)), c.Bool("pure")),
+				shellVersionEnv+"="+ver,
+				shellLevelEnv+"="+strconv.Itoa(level+1),
+			)
+
+			printInfo(fmt.Sprintf("进入 go %s 的 shell，执行 exit 返回", ver))
+			err := runCommand(env, []string{userShell()})
+			printInfo(fmt.Sprintf("已退出 go %s 的 shell", ver))
+			return err
+		},
+	}
+}
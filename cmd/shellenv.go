@@ -0,0 +1,274 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/serious-snow/govm/pkg/utils/path"
+)
+
+const (
+	envBlockBegin = "# >>> govm >>>"
+	envBlockEnd   = "# <<< govm <<<"
+)
+
+var supportedShells = []string{"bash", "zsh", "sh", "fish", "pwsh", "nu", "tcsh"}
+
+func envCommand() *cli.Command {
+	shellFlag := &cli.StringFlag{
+		Name:    "shell",
+		Aliases: []string{"s"},
+		Usage:   "shell syntax: " + strings.Join(supportedShells, "|") + ", default current shell",
+	}
+	return &cli.Command{
+		Name:  "env",
+		Usage: "Print or install environment settings for your shell",
+		UsageText: getCmdLine("env", "[--shell <shell>]") +
+			"\n" + getCmdLine("env", "install|uninstall", "[--shell <shell>]"),
+		Description: "eval the output to set PATH in the current shell, for example:\n" +
+			"  eval \"$(" + getCmdLine("env") + ")\"\n" +
+			"  " + getCmdLine("env", "--shell", "fish") + " | source",
+		Flags: []cli.Flag{shellFlag},
+		Action: func(c *cli.Context) error {
+			script, err := shellEnvScript(shellOrDefault(c.String("shell")))
+			if err != nil {
+				printError(err.Error())
+				return nil
+			}
+			Print(script)
+			return nil
+		},
+		Commands: []*cli.Command{
+			{
+				Name:      "install",
+				Usage:     "Add environment settings to the rc file of the shell",
+				UsageText: getCmdLine("env", "install", "[--shell <shell>]"),
+				Flags:     []cli.Flag{shellFlag},
+				Action: func(c *cli.Context) error {
+					if c.String("shell") == "" {
+						SetEnv()
+						return nil
+					}
+					file, err := installEnvBlock(c.String("shell"))
+					if err != nil {
+						printError("设置环境变量失败：" + err.Error())
+						return nil
+					}
+					printInfo(fmt.Sprintf("环境变量设置于 %s\n可能需要重新打开控制台才能生效", file))
+					return nil
+				},
+			},
+			{
+				Name:      "uninstall",
+				Usage:     "Remove environment settings from the rc file of the shell",
+				UsageText: getCmdLine("env", "uninstall", "[--shell <shell>]"),
+				Flags:     []cli.Flag{shellFlag},
+				Action: func(c *cli.Context) error {
+					if c.String("shell") == "" {
+						UnsetEnv()
+						return nil
+					}
+					printUninstallEnvBlock(c.String("shell"))
+					return nil
+				},
+			},
+		},
+	}
+}
+
+func shellOrDefault(shell string) string {
+	if shell == "" {
+		return defaultShell()
+	}
+	return shell
+}
+
+// shellEnvScript 设置 PATH 的脚本，可用于 eval，也会写入 rc 文件，重复执行不会重复添加
+func shellEnvScript(shell string) (string, error) {
+	p := envPath
+	switch shell {
+	case "bash", "zsh", "sh":
+		return fmt.Sprintf("case \":$PATH:\" in\n  *\":%[1]s:\"*) ;;\n  *) export PATH=\"$PATH:%[1]s\" ;;\nesac\n", p), nil
+	case "fish":
+		return fmt.Sprintf("contains -- '%[1]s' $PATH; or set -gx PATH $PATH '%[1]s'\n", p), nil
+	case "pwsh":
+		return fmt.Sprintf("if (-not (($env:PATH -split [IO.Path]::PathSeparator) -contains '%[1]s')) { $env:PATH = $env:PATH + [IO.Path]::PathSeparator + '%[1]s' }\n", p), nil
+	case "nu":
+		return fmt.Sprintf("if not ('%[1]s' in $env.PATH) { $env.PATH = ($env.PATH | append '%[1]s') }\n", p), nil
+	case "tcsh":
+		return fmt.Sprintf("echo \":${PATH}:\" | grep -q ':%[1]s:' || setenv PATH \"${PATH}:%[1]s\"\n", p), nil
+	default:
+		return "", fmt.Errorf("不支持的 shell：%s，支持：%s", shell, strings.Join(supportedShells, ", "))
+	}
+}
+
+// shellRcFile shell 启动时读取的配置文件
+func shellRcFile(shell string) (string, error) {
+	configDir := filepath.Join(homeDir, ".config")
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		configDir = dir
+	}
+	if isWin {
+		configDir = os.Getenv("APPDATA")
+	}
+
+	switch shell {
+	case "bash":
+		// 与之前的行为保持一致：优先 .bashrc，其次已存在的 .bash_profile
+		file := filepath.Join(homeDir, ".bashrc")
+		if !path.FileIsExisted(file) && path.FileIsExisted(filepath.Join(homeDir, ".bash_profile")) {
+			file = filepath.Join(homeDir, ".bash_profile")
+		}
+		return file, nil
+	case "zsh":
+		dir := homeDir
+		if zdotdir := os.Getenv("ZDOTDIR"); zdotdir != "" {
+			dir = zdotdir
+		}
+		return filepath.Join(dir, ".zshrc"), nil
+	case "sh":
+		return filepath.Join(homeDir, ".profile"), nil
+	case "fish":
+		return filepath.Join(configDir, "fish", "conf.d", "govm.fish"), nil
+	case "pwsh":
+		if isWin {
+			return filepath.Join(homeDir, "Documents", "PowerShell", "Microsoft.PowerShell_profile.ps1"), nil
+		}
+		return filepath.Join(configDir, "powershell", "Microsoft.PowerShell_profile.ps1"), nil
+	case "nu":
+		return filepath.Join(configDir, "nushell", "env.nu"), nil
+	case "tcsh":
+		return filepath.Join(homeDir, ".tcshrc"), nil
+	default:
+		return "", fmt.Errorf("不支持的 shell：%s，支持：%s", shell, strings.Join(supportedShells, ", "))
+	}
+}
+
+// installEnvBlock 在 rc 文件中写入由 envBlockBegin、envBlockEnd 包围的配置，已存在时替换
+func installEnvBlock(shell string) (string, error) {
+	script, err := shellEnvScript(shell)
+	if err != nil {
+		return "", err
+	}
+	file, err := shellRcFile(shell)
+	if err != nil {
+		return "", err
+	}
+
+	content, _, err := readRcFile(file)
+	if err != nil {
+		return "", err
+	}
+	if content, _, err = removeEnvBlock(content); err != nil {
+		return "", fmt.Errorf("%s：%w", file, err)
+	}
+
+	block := envBlockBegin + "\n" + script + envBlockEnd + "\n"
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	if content != "" {
+		content += "\n"
+	}
+	return file, writeRcFile(file, content+block)
+}
+
+// uninstallEnvBlock 从 rc 文件中删除 govm 写入的配置，包括旧版本追加的 export 行
+func uninstallEnvBlock(shell string) (string, bool, error) {
+	file, err := shellRcFile(shell)
+	if err != nil {
+		return "", false, err
+	}
+	content, existed, err := readRcFile(file)
+	if err != nil || !existed {
+		return file, false, err
+	}
+
+	content, removed, err := removeEnvBlock(content)
+	if err != nil {
+		return file, false, fmt.Errorf("%s：%w", file, err)
+	}
+	if !removed {
+		return file, false, nil
+	}
+	return file, true, writeRcFile(file, content)
+}
+
+func printUninstallEnvBlock(shell string) {
+	file, removed, err := uninstallEnvBlock(shell)
+	if err != nil {
+		printError("删除环境变量失败：" + err.Error())
+		return
+	}
+	if !removed {
+		Println(file, "中没有 govm 的环境变量设置")
+		return
+	}
+	printInfo(fmt.Sprintf("已从 %s 删除环境变量设置，可能需要重新打开控制台才能生效", file))
+}
+
+// removeEnvBlock 删除 govm 的配置块以及旧版本追加的 export PATH=$PATH:<envPath> 行，
+// 配置块缺少结束标记时不做修改并返回错误，避免删除开始标记之后的所有内容
+func removeEnvBlock(content string) (string, bool, error) {
+	legacy := "export PATH=$PATH:" + envPath
+	lines := strings.SplitAfter(content, "\n")
+	result := make([]string, 0, len(lines))
+	removed, inBlock := false, false
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == envBlockBegin:
+			inBlock, removed = true, true
+			// 同时删除写入时添加的空行
+			if n := len(result); n > 0 && strings.TrimSpace(result[n-1]) == "" {
+				result = result[:n-1]
+			}
+		case trimmed == envBlockEnd && inBlock:
+			inBlock = false
+		case inBlock:
+		case trimmed == legacy:
+			removed = true
+		default:
+			result = append(result, line)
+		}
+	}
+	if inBlock {
+		return content, false, fmt.Errorf("govm 的配置块缺少结束标记 %s，请手动修复后重试", envBlockEnd)
+	}
+	return strings.Join(result, ""), removed, nil
+}
+
+func readRcFile(file string) (string, bool, error) {
+	// 限制读取大小，防止内存溢出
+	const maxFileSize = 10 * 1024 * 1024 // 10MB
+	info, err := os.Stat(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+	if info.Size() > maxFileSize {
+		return "", true, fmt.Errorf("配置文件过大，无法安全读取：%s", file)
+	}
+	buf, err := os.ReadFile(file)
+	if err != nil {
+		return "", true, err
+	}
+	return string(buf), true, nil
+}
+
+func writeRcFile(file, content string) error {
+	if err := path.MakeDir(filepath.Dir(file)); err != nil {
+		return err
+	}
+	mode := os.FileMode(0o644)
+	if info, err := os.Stat(file); err == nil {
+		mode = info.Mode().Perm()
+	}
+	return os.WriteFile(file, []byte(content), mode)
+}
@@ -0,0 +1,21 @@
+package cmd
+
+import "testing"
+
+func TestRemoveEnvBlock(t *testing.T) {
+	content := "alias ll='ls -l'\n\n" + envBlockBegin + "\nexport PATH=/x:$PATH\n" + envBlockEnd + "\nexport EDITOR=vim\n"
+	got, removed, err := removeEnvBlock(content)
+	if err != nil || !removed {
+		t.Fatalf("removed = %v, err = %v", removed, err)
+	}
+	if want := "alias ll='ls -l'\nexport EDITOR=vim\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// 缺少结束标记时不修改内容，避免删除开始标记之后的所有行
+	content = "alias ll='ls -l'\n" + envBlockBegin + "\nexport PATH=/x:$PATH\nexport EDITOR=vim\n"
+	got, removed, err = removeEnvBlock(content)
+	if err == nil || removed || got != content {
+		t.Errorf("got %q, removed = %v, err = %v", got, removed, err)
+	}
+}
@@ -6,9 +6,10 @@ import (
 
 func unholdCommand() *cli.Command {
 	return &cli.Command{
-		Name:      "unhold",
-		Usage:     "Cancel a hold command for a version",
-		UsageText: getCmdLine("unhold", "<version>"),
+		Name:          "unhold",
+		Usage:         "Cancel a hold command for a version",
+		UsageText:     getCmdLine("unhold", "<version>"),
+		ShellComplete: completeArgs(1, holdCandidates),
 		Action: func(c *cli.Context) error {
 			v := c.Args().Get(0)
 			if v == "" {
@@ -21,24 +22,9 @@ func unholdCommand() *cli.Command {
 }
 
 func unhold(v string) {
-	v = trimVersion(v)
-	if !isHold(v) {
-		return
-	}
-
-	tempHoldVersions := make([]string, 0, len(holdVersions))
-
-	for _, i2 := range holdVersions {
-		if i2 == v {
-			continue
-		}
-
-		tempHoldVersions = append(tempHoldVersions, i2)
-	}
-
-	holdVersions = tempHoldVersions
-
-	if err := saveLocalHoldVersion(); err != nil {
+	if err := mgr.Unhold(v); err != nil {
 		printError("保存hold版本失败：" + err.Error())
+		return
 	}
+	readLocalHoldVersion()
 }
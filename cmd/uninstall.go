@@ -2,53 +2,159 @@ package cmd
 
 import (
 	"os"
-	"path/filepath"
 
 	"github.com/urfave/cli/v3"
 
+	"github.com/serious-snow/govm/pkg/history"
+	"github.com/serious-snow/govm/pkg/manager"
 	"github.com/serious-snow/govm/pkg/utils/path"
 )
 
 func uninstallCommand() *cli.Command {
 	return &cli.Command{
-		Name:      "uninstall",
-		Aliases:   []string{"ui"},
-		Usage:     "Uninstall a <version>",
-		UsageText: getCmdLine("uninstall", "<version>"),
+		Name:    "uninstall",
+		Aliases: []string{"ui"},
+		Usage:   "Uninstall <version>...",
+		UsageText: getCmdLine("uninstall", "[--force]", "[--purge-cache]", "<version>...") +
+			"\n" + getCmdLine("uninstall", "1.20.*") +
+			"\n" + getCmdLine("uninstall", "--all-except-active"),
+		Description: "<version> can be a pattern such as 1.20.* that matches installed versions,\n" +
+			"the current version and versions required by the project in the current directory\n" +
+			"or registered projects are not uninstalled unless --force is given",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "force",
+				Aliases: []string{"f"},
+				Usage:   "uninstall the current version or versions required by projects",
+			},
+			&cli.BoolFlag{
+				Name:  "purge-cache",
+				Usage: "also remove the archive from the cache",
+			},
+			&cli.BoolFlag{
+				Name:  "all-except-active",
+				Usage: "uninstall all versions except the current version",
+			},
+		},
+		ShellComplete: completeArgs(0, uninstallCandidates),
 		Action: func(c *cli.Context) error {
-			v := c.Args().Get(0)
-			if v == "" {
-				return cli.ShowSubcommandHelp(c)
+			args := splitVersionArgs(c.Args().Slice())
+			if c.Bool("all-except-active") {
+				args = args[:0]
+				for _, v := range localInstallVersions {
+					if !v.Equal(currentUse) {
+						args = append(args, v.String())
+					}
+				}
+				if len(args) == 0 {
+					Println("没有需要卸载的版本")
+					return nil
+				}
+			}
+			if len(args) == 0 {
+				if !isInteractive() {
+					return cli.ShowSubcommandHelp(c)
+				}
+				v := pickVersion("选择要卸载的版本", installedItems())
+				if v == "" {
+					return nil
+				}
+				args = append(args, v)
+			}
+
+			if !uninstallVersions(args, c.Bool("force"), c.Bool("purge-cache")) {
+				return cli.Exit("", 1)
 			}
-			uninstallVersion(v)
 			return nil
 		},
 	}
 }
 
-func uninstallVersion(version string) {
-	version = trimVersion(version)
+// expandUninstallArgs 将 1.20.* 这样的模式展开为匹配的已安装版本，去除重复的版本
+func expandUninstallArgs(args []string) ([]string, bool) {
+	ok := true
+	seen := map[string]bool{}
+	result := make([]string, 0, len(args))
+	add := func(v string) {
+		if !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+	for _, arg := range args {
+		arg = manager.TrimVersion(arg)
+		if !manager.IsPattern(arg) {
+			add(arg)
+			continue
+		}
+		matched := false
+		for _, v := range localInstallVersions {
+			if manager.MatchPattern(arg, *v) {
+				matched = true
+				add(v.String())
+			}
+		}
+		if !matched {
+			printError("没有匹配 " + arg + " 的已安装版本")
+			ok = false
+		}
+	}
+	return result, ok
+}
+
+// uninstallVersions 卸载多个版本，全部成功时返回 true
+func uninstallVersions(args []string, force, purgeCache bool) bool {
+	versions, ok := expandUninstallArgs(args)
+	referenced := referencedVersions()
+	for _, v := range versions {
+		if !uninstallVersion(v, force, purgeCache, referenced) {
+			ok = false
+		}
+	}
+	return ok
+}
+
+func uninstallVersion(version string, force, purgeCache bool, referenced map[string]string) bool {
+	version = manager.TrimVersion(version)
 
 	if !isInInstall(version) {
 		printError(version + " 未安装")
-		return
+		return false
+	}
+
+	active := currentUse.Valid() && currentUse.String() == version
+	if !force {
+		switch file := referenced[version]; {
+		case active:
+			printError(version + " 是当前使用的版本，卸载后 go 命令将不可用，如需卸载，请执行：")
+			printCmdLine("uninstall", "--force", version)
+			return false
+		case file != "":
+			printError(version + " 被 " + file + " 使用，如需卸载，请执行：")
+			printCmdLine("uninstall", "--force", version)
+			return false
+		}
 	}
-	defer func() {
-		fileName := getDownloadFilename(version)
 
+	// 卸载当前激活的版本时会先取消激活
+	if err := mgr.Uninstall(version); err != nil {
+		printError(version + " 卸载失败：" + err.Error())
+		return false
+	}
+	readCurrentUseVersion()
+	readLocalInstallVersion()
+	readLocalHoldVersion()
+	recordHistory(history.ActionUninstall, version, "")
+
+	if purgeCache {
+		fileName := getDownloadFilename(version)
 		if path.FileIsExisted(fileName) {
 			if err := os.Remove(fileName); err != nil {
 				printError("删除缓存文件失败：" + err.Error())
 			}
 		}
-	}()
-	err := os.RemoveAll(filepath.Join(conf.InstallPath, version))
-	if err != nil {
-		printError(version + " 卸载失败：" + err.Error())
-		return
 	}
 
-	unhold(version)
-
 	Println(version, "卸载成功")
+	return true
 }
@@ -1,8 +1,6 @@
 package cmd
 
 import (
-	"os"
-
 	"github.com/urfave/cli/v3"
 )
 
@@ -17,10 +15,11 @@ func unuseCommand() *cli.Command {
 				printError("当前没有激活的版本")
 				return nil
 			}
-			if err := os.Remove(linkPath); err != nil && !os.IsNotExist(err) {
+			if err := mgr.Unuse(); err != nil {
 				printError("删除软连接失败：" + err.Error())
 				return nil
 			}
+			readCurrentUseVersion()
 			return nil
 		},
 	}
@@ -1,7 +1,12 @@
 package cmd
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/urfave/cli/v3"
+
+	"github.com/serious-snow/govm/pkg/manager"
 )
 
 func updateCommand() *cli.Command {
@@ -11,15 +16,16 @@ func updateCommand() *cli.Command {
 		UsageText: getCmdLine("update"),
 		Action: func(c *cli.Context) error {
 			checkGovmUpdate(c.Context)
-			reloadAvailable()
+			reloadAvailable(c.Context)
 			printCanUpgradeCount()
+			printUnsupported()
 			return nil
 		},
 	}
 }
 
 func printCanUpgradeCount() {
-	m := getUpgradeableList()
+	m := mgr.Upgradeable()
 	canUpgradeCount := 0
 
 	for _, versions := range m {
@@ -32,3 +38,23 @@ func printCanUpgradeCount() {
 
 	Printf("%d 个版本有最新版本, 执行 %s 查看更多信息\n", canUpgradeCount, getCmdLine("list --upgradeable"))
 }
+
+// printUnsupported 提示当前使用或被保留的版本已停止维护
+func printUnsupported() {
+	minors := mgr.SupportedMinors()
+	if len(minors) == 0 {
+		return
+	}
+
+	for _, v := range localInstallVersions {
+		if mgr.Support(*v) != manager.EOL {
+			continue
+		}
+		switch {
+		case v.Equal(currentUse):
+			printError(fmt.Sprintf("当前使用的版本 %s 已停止维护，仍在维护的版本：%s", v, strings.Join(minors, ", ")))
+		case isHold(v.String()):
+			printError(fmt.Sprintf("被保留的版本 %s 已停止维护%s，仍在维护的版本：%s", v, holdNote(v.String()), strings.Join(minors, ", ")))
+		}
+	}
+}
@@ -1,190 +1,212 @@
 package cmd
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
 	"strings"
 
+	"github.com/manifoldco/promptui"
 	"github.com/urfave/cli/v3"
 
+	"github.com/serious-snow/govm/pkg/history"
+	"github.com/serious-snow/govm/pkg/manager"
+	"github.com/serious-snow/govm/pkg/notes"
 	"github.com/serious-snow/govm/pkg/version"
 )
 
 func upgradeCommand() *cli.Command {
 	return &cli.Command{
-		Name:      "upgrade",
-		Usage:     "Upgrade outdated version list",
-		UsageText: getCmdLine("upgrade"),
+		Name:  "upgrade",
+		Usage: "Upgrade outdated version list",
+		UsageText: getCmdLine("upgrade", "[--dry-run [--notes]]", "[--keep-old]", "[--minor]", "[--interactive]", "[version...]") +
+			"\n" + getCmdLine("upgrade", "govm"),
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "dry-run",
+				Aliases: []string{"n"},
+				Usage:   "print the upgrade plan without executing it",
+			},
+			&cli.BoolFlag{
+				Name:  "notes",
+				Usage: "show release notes of the skipped patches in the upgrade plan",
+			},
+			&cli.BoolFlag{
+				Name:    "keep-old",
+				Aliases: []string{"k"},
+				Usage:   "install new versions without uninstalling old ones",
+			},
+			&cli.BoolFlag{
+				Name:    "minor",
+				Aliases: []string{"m"},
+				Usage:   "upgrade the newest installed minor or the given versions to the newest stable minor version",
+			},
+			&cli.BoolFlag{
+				Name:    "interactive",
+				Aliases: []string{"i"},
+				Usage:   "confirm each step of the upgrade plan",
+			},
+		},
+		ShellComplete: completeArgs(0, installedCandidates),
 		Action: func(c *cli.Context) error {
-			v := c.Args().Get(0)
-			switch v {
-			case "govm":
+			if c.Args().Get(0) == "govm" {
 				upgradeGOVM(c.Context)
 				return nil
-			default:
-				if v != "" {
-					upgrade(v)
-					return nil
-				}
-				upgradeAll()
-				return nil
 			}
+
+			opts := manager.UpgradeOptions{
+				KeepOld: c.Bool("keep-old"),
+				Minor:   c.Bool("minor"),
+			}
+			upgrade(c.Context, opts, c.Bool("dry-run"), c.Bool("interactive"), c.Bool("notes"), c.Args().Slice()...)
+			return nil
 		},
 	}
 }
 
-func upgrade(v string) {
-	v = trimVersion(v)
-	if !isInInstall(v) {
-		printError(v + " 未安装")
+// upgrade 未指定版本时升级所有可升级版本
+func upgrade(ctx context.Context, opts manager.UpgradeOptions, dryRun, interactive, withNotes bool, versions ...string) {
+	steps, err := mgr.PlanUpgrade(opts, versions...)
+	if err != nil {
+		var verr *manager.VersionError
+		switch {
+		case errors.As(err, &verr) && errors.Is(err, manager.ErrHeld):
+			printError(verr.Version + " 被标记为保留" + holdNote(verr.Version))
+		case errors.As(err, &verr) && errors.Is(err, manager.ErrNotFound):
+			printError(verr.Version + " 找不到最新版本")
+		default:
+			printError(err.Error())
+		}
 		return
 	}
-	if isHold(v) {
-		printError(v + " 被标记为保留")
+
+	if len(steps) == 0 {
+		if len(versions) == 0 {
+			Println("没有可升级的版本")
+		} else {
+			Println(strings.Join(versions, ", "), "已经最新版本")
+		}
 		return
 	}
 
-	current := *version.New(v)
-
-	newest := getPatchNewestVersion(current)
+	var noteList []*notes.Note
+	if withNotes {
+		targets := make([]*version.Version, 0, len(steps))
+		for i := range steps {
+			targets = append(targets, &steps[i].To)
+		}
+		noteList = loadNotes(newestVersion(targets), false)
+	}
 
-	if newest == nil {
-		printError(v + " 找不到最新版本")
+	printUpgradePlan(steps, opts, noteList)
+	if dryRun {
 		return
 	}
 
-	if version.Equal(current, *newest) {
-		Println(current, "已经最新版本")
-		return
+	if interactive {
+		steps = confirmUpgradeSteps(steps)
+		if len(steps) == 0 {
+			return
+		}
 	}
 
-	upgradeVersions(map[string][]*version.Version{
-		newest.String(): {
-			&current,
-		},
-	})
+	upgradeVersions(ctx, steps, opts)
 }
 
-func upgradeAll() {
-	m := getUpgradeableList()
-	if len(m) == 0 {
-		Println("没有可升级的版本")
-		return
-	}
+// printUpgradePlan 输出升级计划，noteList 不为空时同时输出跳过的版本的发布说明
+func printUpgradePlan(steps []manager.UpgradeStep, opts manager.UpgradeOptions, noteList []*notes.Note) {
 	sb := strings.Builder{}
-	for s, versions := range m {
-		for _, v := range versions {
-			if isHold(v.String()) {
-				continue
-			}
-			sb.WriteString(v.String())
-			sb.WriteString(" -> ")
-			sb.WriteString(s)
-			sb.WriteString("\n")
+	sbHold := strings.Builder{}
+	for _, step := range steps {
+		if step.Hold != nil {
+			sbHold.WriteString(step.From.String())
+			sbHold.WriteString(" ")
+			sbHold.WriteString(holdNote(step.From.String()))
+			sbHold.WriteString("\n")
+			continue
+		}
+		sb.WriteString(step.From.String())
+		sb.WriteString(" -> ")
+		sb.WriteString(step.To.String())
+		if opts.KeepOld {
+			sb.WriteString(" (保留旧版本)")
 		}
+		sb.WriteString("\n")
+		writeUpgradeNotes(&sb, noteList, step.From, step.To)
 	}
 
 	if len(sb.String()) != 0 {
 		Println("升级版本：")
 		Print(sb.String())
 	}
-	upgradeVersions(m)
+	if len(sbHold.String()) != 0 {
+		Println("跳过被保留的版本：")
+		Print(sbHold.String())
+	}
 }
 
-func upgradeVersions(m map[string][]*version.Version) {
-	var (
-		installCount   int
-		uninstallCount int
-		ignoreCount    int
-	)
-
-	for s, versions := range m {
-
-		canInstall := false
-		for _, v := range versions {
-			if !isHold(v.String()) {
-				canInstall = true
-				break
-			}
-		}
-
-		if !canInstall {
-			ignoreCount += len(versions)
+// confirmUpgradeSteps 逐个确认升级步骤，返回确认后的步骤
+func confirmUpgradeSteps(steps []manager.UpgradeStep) []manager.UpgradeStep {
+	confirmed := make([]manager.UpgradeStep, 0, len(steps))
+	for _, step := range steps {
+		if step.Hold != nil {
 			continue
 		}
-
-		if !isInInstall(s) {
-			if err := silentInstall(s, false); err != nil {
-				printError(err.Error())
-				continue
-			}
-			installCount++
+		prompt := promptui.Prompt{
+			Label:     fmt.Sprintf("升级 %s -> %s", step.From, step.To),
+			IsConfirm: true,
+			Default:   "y",
 		}
-
-		for _, v := range versions {
-			if isHold(v.String()) {
-				ignoreCount++
-				continue
-			}
-
-			uninstallVersion(v.String())
-			uninstallCount++
-
-			// 如果卸载的是当前正在使用的 就设置为刚刚的最新版本
-			if version.Equal(currentUse, *v) {
-				readLocalInstallVersion()
-				useVersion(s)
-				readCurrentUseVersion()
-			}
+		v, err := prompt.Run()
+		if errors.Is(err, promptui.ErrInterrupt) {
+			os.Exit(130)
 		}
-	}
-
-	if installCount+uninstallCount != 0 {
-		readLocalInstallVersion()
-	}
-
-	Printf("共升级 %d 个版本, 安装了 %d 个版本, 卸载了 %d 个版本，忽略了 %d 个版本\n", len(m), installCount, uninstallCount, ignoreCount)
-}
-
-func getPatchNewestVersion(v version.Version) *version.Version {
-	for _, v2 := range remoteVersion.Go {
-		if v2.Version.Major == v.Major && v2.Version.Minor == v.Minor {
-			return &v2.Version
+		if strings.ToLower(v) == "y" || (err == nil && v == "") {
+			confirmed = append(confirmed, step)
 		}
 	}
-	return nil
+	return confirmed
 }
 
-// 获取可以更新的列表
+func upgradeVersions(ctx context.Context, steps []manager.UpgradeStep, opts manager.UpgradeOptions) {
+	result := mgr.ApplyUpgrade(ctx, steps, opts)
 
-func getUpgradeableList() map[string][]*version.Version {
-	result := make(map[string][]*version.Version)
-
-	m := GetPatchNewestVersionMap()
+	for _, err := range result.Errors {
+		if errors.Is(err, manager.ErrUpgradeRunning) {
+			printError(err.Error() + "，请等待其完成后重试")
+			return
+		}
+		printError(err.Error())
+	}
 
-	for _, v := range localInstallVersions {
-		mVer := v.MinorVersion()
+	readLocalInstallVersion()
+	readCurrentUseVersion()
+	readLocalHoldVersion()
+	enforceCacheMaxSize()
 
-		latest := m[mVer]
-		if latest == nil {
-			continue
-		}
+	if result.RolledBack {
+		printError("升级失败，已回滚到升级前的状态")
+		return
+	}
 
-		if version.Equal(*latest, *v) {
-			continue
+	for _, step := range steps {
+		if step.Hold == nil {
+			recordHistory(history.ActionUpgrade, step.From.String(), step.To.String())
 		}
-
-		result[latest.String()] = append(result[latest.String()], v)
 	}
-	return result
+	Printf("共升级 %d 个版本, 安装了 %d 个版本, 卸载了 %d 个版本，忽略了 %d 个版本\n", result.Total, result.Installed, result.Uninstalled, result.Ignored)
+	autoGC()
 }
 
-// 1.18->1.18.10
-func GetPatchNewestVersionMap() map[string]*version.Version {
-	result := make(map[string]*version.Version)
-	for _, cacheVersion := range remoteVersion.Go {
-		old := cacheVersion.Version.MinorVersion()
-		if _, ok := result[old]; !ok {
-			result[old] = &cacheVersion.Version
-		}
+// recoverUpgrade 处理上次中断的升级
+func recoverUpgrade() {
+	recovered, err := mgr.Recover()
+	if err != nil {
+		printError("恢复中断的升级失败：" + err.Error())
+		return
+	}
+	if recovered {
+		printInfo("检测到上次升级被中断，已恢复")
 	}
-	return result
 }
@@ -1,24 +1,37 @@
 package cmd
 
 import (
-	"os"
-	"path/filepath"
+	"errors"
 
 	"github.com/urfave/cli/v3"
 
-	"github.com/serious-snow/govm/pkg/utils/path"
+	"github.com/serious-snow/govm/pkg/history"
+	"github.com/serious-snow/govm/pkg/manager"
 )
 
 func useCommand() *cli.Command {
 	return &cli.Command{
-		Name:      "use",
-		Aliases:   []string{"u"},
-		Usage:     "Active a <version>",
-		UsageText: getCmdLine("use", "<version>"),
+		Name:          "use",
+		Aliases:       []string{"u"},
+		Usage:         "Active a <version>",
+		UsageText:     getCmdLine("use", "<version>") + "\n" + getCmdLine("use", "-"),
+		Description:   "use - activates the previously active version, like cd -",
+		ShellComplete: completeArgs(1, useCandidates),
 		Action: func(c *cli.Context) error {
 			v := c.Args().Get(0)
 			if v == "" {
-				return cli.ShowSubcommandHelp(c)
+				if !isInteractive() {
+					return cli.ShowSubcommandHelp(c)
+				}
+				if v = pickVersion("选择要激活的版本", installedItems()); v == "" {
+					return nil
+				}
+			}
+			if v == "-" {
+				if v = previousVersion(); v == "" {
+					printError("没有上一个使用的版本")
+					return cli.Exit("", 1)
+				}
 			}
 			useVersion(v)
 			return nil
@@ -27,7 +40,7 @@ func useCommand() *cli.Command {
 }
 
 func useVersion(version string) {
-	version = trimVersion(version)
+	version = resolveAlias(manager.TrimVersion(version), ActionUse)
 
 	if !isInInstall(version) {
 		suggest := suggestVersion(version, ActionUse)
@@ -38,18 +51,22 @@ func useVersion(version string) {
 		}
 		version = suggest
 	}
-	goRoot := filepath.Join(conf.InstallPath, version, "go")
-	if !path.PathIsExisted(goRoot) {
-		printError("go 文件夹不存在，请重新安装")
-		return
-	}
 
-	if err := os.Remove(linkPath); err != nil && !os.IsNotExist(err) {
-		printError("删除旧软连接失败：" + err.Error())
-		return
+	from := ""
+	if currentUse.Valid() {
+		from = currentUse.String()
 	}
-	err := Symlink(goRoot, linkPath)
-	if err != nil {
+	if err := mgr.Use(version); err != nil {
+		if errors.Is(err, manager.ErrBrokenInstall) {
+			printError(err.Error())
+			return
+		}
 		printError("创建软连接失败：" + err.Error())
+		return
+	}
+	readCurrentUseVersion()
+	// 重复激活当前版本不记录，避免历史中出现没有变化的记录
+	if from != version {
+		recordHistory(history.ActionUse, from, version)
 	}
 }
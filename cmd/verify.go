@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v3"
+
+	"github.com/serious-snow/govm/pkg/manager"
+)
+
+// maxDiffLines 每类差异最多输出的文件数
+const maxDiffLines = 10
+
+func verifyCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "verify",
+		Usage:     "Verify installed versions against the manifest recorded at install time",
+		UsageText: getCmdLine("verify", "[--quick]", "[<version>...]"),
+		Description: "verifies all installed versions if no <version> is given,\n" +
+			"exits with 1 if any file is modified, missing or added, use repair to restore them",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "quick",
+				Aliases: []string{"q"},
+				Usage:   "compare file sizes only without hashing",
+			},
+		},
+		ShellComplete: completeArgs(0, installedCandidates),
+		Action: func(c *cli.Context) error {
+			versions := c.Args().Slice()
+			if len(versions) == 0 {
+				versions = installedCandidates()
+			}
+			if len(versions) == 0 {
+				Println("没有已安装的版本")
+				return nil
+			}
+			if !verifyVersions(versions, c.Bool("quick")) {
+				return cli.Exit("", 1)
+			}
+			return nil
+		},
+	}
+}
+
+func repairCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "repair",
+		Usage:     "Restore modified or missing files of installed versions from the cached archive",
+		UsageText: getCmdLine("repair", "[--ignore-sha256]", "<version>..."),
+		Description: "files not in the manifest are removed, the archive is downloaded again if it is not cached,\n" +
+			"versions installed without a manifest get one from the archive",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "ignore-sha256",
+				Aliases: []string{"i"},
+				Usage:   "ignore check sha256",
+			},
+		},
+		ShellComplete: completeArgs(0, installedCandidates),
+		Action: func(c *cli.Context) error {
+			if c.NArg() == 0 {
+				return cli.ShowSubcommandHelp(c)
+			}
+			if !repairVersions(c.Context, c.Args().Slice(), c.Bool("ignore-sha256")) {
+				return cli.Exit("", 1)
+			}
+			return nil
+		},
+	}
+}
+
+// verifyVersions 校验已安装的版本，全部通过时返回 true
+func verifyVersions(versions []string, quick bool) bool {
+	ok := true
+	for _, ver := range versions {
+		ver = manager.TrimVersion(ver)
+		diff, err := mgr.Verify(ver, quick)
+		if err != nil {
+			if errors.Is(err, manager.ErrNoManifest) {
+				printError(err.Error() + "，可使用 govm repair " + ver + " 根据归档文件生成")
+			} else {
+				printError(ver + " 校验失败：" + err.Error())
+			}
+			ok = false
+			continue
+		}
+		if diff.Clean() {
+			Println(ver, color.GreenString("校验通过")+slimNote(ver))
+			continue
+		}
+		ok = false
+		Println(ver, color.RedString("与安装时不一致")+slimNote(ver))
+		printTreeDiff(diff)
+	}
+	return ok
+}
+
+// repairVersions 修复已安装的版本，全部成功时返回 true
+func repairVersions(ctx context.Context, versions []string, ignore bool) bool {
+	ok := true
+	for _, ver := range versions {
+		ver = manager.TrimVersion(ver)
+		diff, err := mgr.Repair(ctx, ver, manager.FetchOptions{IgnoreSha256: ignore})
+		if err != nil {
+			printError(ver + " 修复失败：" + err.Error())
+			ok = false
+			continue
+		}
+		if diff.Clean() {
+			Println(ver, "没有需要修复的文件"+slimNote(ver))
+			continue
+		}
+		printTreeDiff(diff)
+		Println(ver, color.GreenString("修复成功")+slimNote(ver))
+	}
+	return ok
+}
+
+func printTreeDiff(diff *manager.TreeDiff) {
+	printFiles := func(files []string, mark string, c *color.Color) {
+		for i, file := range files {
+			if i == maxDiffLines {
+				Printf("    ... 共 %d 个\n", len(files))
+				return
+			}
+			Println("   ", c.Sprint(mark), file)
+		}
+	}
+	printFiles(diff.Modified, "M", color.New(color.FgYellow))
+	printFiles(diff.Missing, "D", color.New(color.FgRed))
+	printFiles(diff.Added, "+", color.New(color.FgCyan))
+	Println("   ", fmt.Sprintf("修改 %d，缺失 %d，新增 %d", len(diff.Modified), len(diff.Missing), len(diff.Added)))
+}
+
+// slimNote 精简安装的说明，排除的文件是有意省略的，不会被视为缺失，也不会被修复
+func slimNote(ver string) string {
+	meta := installMetas[ver]
+	if meta == nil || meta.Slim == nil {
+		return ""
+	}
+	return fmt.Sprintf("（精简安装 %s，已排除 %s）", meta.Slim.Name, strings.Join(meta.Slim.Exclude, ", "))
+}
@@ -11,7 +11,25 @@ type Config struct {
 	CachePath   string `yaml:"cachePath"`
 	InstallPath string `yaml:"installPath"`
 	AutoSetEnv  *bool  `yaml:"autoSetEnv"` // 自动设置环境变量
-	path        string
+	// CacheMaxSize 缓存目录大小上限，如 2GB，每次安装后按最近最少使用的顺序清理，为空时不限制
+	CacheMaxSize string `yaml:"cacheMaxSize,omitempty"`
+	// Dedupe 安装时将与其他已安装版本相同的文件替换为硬链接，节省磁盘空间
+	Dedupe bool `yaml:"dedupe,omitempty"`
+	// SlimProfiles 精简安装的配置，名称对应排除的路径模式，如 default: [api, doc, misc, test, "**/testdata"]，
+	// 可覆盖内置的 default
+	SlimProfiles map[string][]string `yaml:"slimProfiles,omitempty"`
+	// GC 旧版本的保留策略，为空时 upgrade 之后不自动清理
+	GC   *GCPolicy `yaml:"gc,omitempty"`
+	path string
+}
+
+// GCPolicy 清理旧版本时的保留策略，保留条件为空时默认保留
+type GCPolicy struct {
+	AfterUpgrade       bool  `yaml:"afterUpgrade"` // upgrade 之后自动清理
+	KeepLatestPerMinor *bool `yaml:"keepLatestPerMinor,omitempty"`
+	KeepHeld           *bool `yaml:"keepHeld,omitempty"`
+	KeepActive         *bool `yaml:"keepActive,omitempty"`
+	KeepReferenced     *bool `yaml:"keepReferenced,omitempty"`
 }
 
 func (c *Config) Sync() {
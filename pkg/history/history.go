@@ -0,0 +1,79 @@
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"time"
+)
+
+// 记录的操作
+const (
+	ActionUse       = "use"
+	ActionInstall   = "install"
+	ActionUninstall = "uninstall"
+	ActionUpgrade   = "upgrade"
+)
+
+// Entry 一条操作记录，From 为操作前的版本，To 为操作后的版本，没有时为空
+type Entry struct {
+	Time   time.Time `json:"time"`
+	Action string    `json:"action"`
+	From   string    `json:"from,omitempty"`
+	To     string    `json:"to,omitempty"`
+}
+
+// Append 追加一条记录，每条记录一行 json，文件不存在时创建
+func Append(file string, entry Entry) error {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	buf, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(file, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(buf, '\n')); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Read 按时间顺序读取所有记录，文件不存在时返回空，无法解析的行会被跳过
+func Read(file string) ([]Entry, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	entries := make([]Entry, 0)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// Previous 上一个激活的版本，类似 cd -，从最近的 use 记录中查找不同于 current 的激活前版本
+func Previous(entries []Entry, current string) string {
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		if entry.Action != ActionUse || entry.From == "" || entry.From == current {
+			continue
+		}
+		return entry.From
+	}
+	return ""
+}
@@ -0,0 +1,38 @@
+package history
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestPrevious(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "history.log")
+	for _, entry := range []Entry{
+		{Action: ActionUse, To: "1.21.5"},
+		{Action: ActionInstall, To: "1.22.0"},
+		{Action: ActionUse, From: "1.21.5", To: "1.22.0"},
+		{Action: ActionUse, From: "1.22.0", To: "1.22.0"},
+	} {
+		if err := Append(file, entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	entries, err := Read(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 4 || entries[0].Time.IsZero() {
+		t.Fatalf("got %+v", entries)
+	}
+
+	if got := Previous(entries, "1.22.0"); got != "1.21.5" {
+		t.Errorf("Previous = %q", got)
+	}
+	// 当前版本被卸载后，回到最近一次激活前的版本
+	if got := Previous(entries, ""); got != "1.22.0" {
+		t.Errorf("Previous = %q", got)
+	}
+	if got := Previous(nil, "1.22.0"); got != "" {
+		t.Errorf("Previous = %q", got)
+	}
+}
@@ -0,0 +1,335 @@
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/serious-snow/govm/pkg/utils"
+	"github.com/serious-snow/govm/pkg/version"
+)
+
+// archiveReg 归档文件名，如 go1.21.5.linux-amd64.tar.gz，下载中的文件以 .temp 结尾
+var archiveReg = regexp.MustCompile(`^go(.+)\.([a-z0-9]+)-([a-z0-9]+)\.(tar\.gz|zip)(\.temp)?$`)
+
+// CacheEntry 缓存目录中的归档文件
+type CacheEntry struct {
+	Name     string
+	Path     string
+	Version  version.Version
+	Platform string // 如 linux/amd64
+	Size     int64
+	ModTime  time.Time // 下载或最近一次使用的时间
+	Partial  bool      // 未下载完成的 .temp 文件
+}
+
+// Host 是否为本机平台的归档文件
+func (e *CacheEntry) Host() bool {
+	return e.Platform == runtime.GOOS+"/"+runtime.GOARCH
+}
+
+// CacheStatus 归档文件的校验状态
+type CacheStatus string
+
+const (
+	CacheOK       CacheStatus = "ok"       // sha256 校验通过
+	CacheMismatch CacheStatus = "mismatch" // sha256 校验不通过
+	CacheUnknown  CacheStatus = "unknown"  // 远程列表中没有该文件的 sha256，无法校验
+	CachePartial  CacheStatus = "partial"  // 未下载完成
+	// CacheUnverified 远程列表中有 sha256，但还没有校验记录或文件在校验后发生了变化
+	CacheUnverified CacheStatus = "unverified"
+)
+
+// cacheChecksFile 归档文件的校验记录，cache list 据此显示校验状态，不需要重新计算 sha256
+const cacheChecksFile = "checks.json"
+
+// cacheCheckRecord 一个归档文件最近一次的校验结果，Size、Sha256 与校验时不同时记录失效
+type cacheCheckRecord struct {
+	Size      int64       `json:"size"`
+	Sha256    string      `json:"sha256"`
+	Status    CacheStatus `json:"status"`
+	CheckedAt time.Time   `json:"checkedAt"`
+}
+
+// PruneOptions 清理缓存的选项，零值的条件不生效
+type PruneOptions struct {
+	KeepInstalled bool          // 保留已安装版本的归档文件
+	OlderThan     time.Duration // 删除超过该时间未使用的归档文件
+	MaxSize       int64         // 缓存大小上限，超出时按最近最少使用的顺序删除
+	TempAge       time.Duration // 删除超过该时间未修改的 .temp 文件，为 0 时使用 time.Hour
+	DryRun        bool          // 只返回要删除的文件
+}
+
+// CacheEntries 缓存目录中的归档文件，按使用时间从旧到新排序
+func (m *Manager) CacheEntries() ([]*CacheEntry, error) {
+	dirEntries, err := os.ReadDir(m.conf.CachePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	entries := make([]*CacheEntry, 0, len(dirEntries))
+	for _, dirEntry := range dirEntries {
+		if dirEntry.IsDir() {
+			continue
+		}
+		match := archiveReg.FindStringSubmatch(dirEntry.Name())
+		if match == nil {
+			continue
+		}
+		info, err := dirEntry.Info()
+		if err != nil {
+			continue
+		}
+		entries = append(entries, &CacheEntry{
+			Name:     dirEntry.Name(),
+			Path:     filepath.Join(m.conf.CachePath, dirEntry.Name()),
+			Version:  *version.New(match[1]),
+			Platform: match[2] + "/" + match[3],
+			Size:     info.Size(),
+			ModTime:  info.ModTime(),
+			Partial:  match[5] != "",
+		})
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].ModTime.Before(entries[j].ModTime)
+	})
+	return entries, nil
+}
+
+// CacheSize 缓存目录中归档文件的总大小
+func (m *Manager) CacheSize() (int64, error) {
+	entries, err := m.CacheEntries()
+	if err != nil {
+		return 0, err
+	}
+	size := int64(0)
+	for _, entry := range entries {
+		size += entry.Size
+	}
+	return size, nil
+}
+
+// CheckCache 校验归档文件，只有本机平台且在远程列表中的文件才能校验
+func (m *Manager) CheckCache(entry *CacheEntry) CacheStatus {
+	if entry.Partial {
+		return CachePartial
+	}
+	info := m.RemoteInfo(entry.Version)
+	if !entry.Host() || info == nil || info.Sha256 == "" || info.Filename != entry.Name {
+		return CacheUnknown
+	}
+	if utils.CheckSha256(entry.Path, info.Sha256) {
+		return CacheOK
+	}
+	return CacheMismatch
+}
+
+// CachedStatus 根据最近一次校验的记录返回归档文件的校验状态，不读取文件内容，
+// 校验由 VerifyCache 和下载时的 sha256 校验记录
+func (m *Manager) CachedStatus(entry *CacheEntry) CacheStatus {
+	if entry.Partial {
+		return CachePartial
+	}
+	info := m.RemoteInfo(entry.Version)
+	if !entry.Host() || info == nil || info.Sha256 == "" || info.Filename != entry.Name {
+		return CacheUnknown
+	}
+	record := m.loadCacheChecks()[entry.Name]
+	if record == nil || record.Size != entry.Size || record.Sha256 != info.Sha256 {
+		return CacheUnverified
+	}
+	return record.Status
+}
+
+func (m *Manager) loadCacheChecks() map[string]*cacheCheckRecord {
+	records := map[string]*cacheCheckRecord{}
+	buf, err := os.ReadFile(filepath.Join(m.conf.CachePath, cacheChecksFile))
+	if err != nil {
+		return records
+	}
+	_ = json.Unmarshal(buf, &records)
+	return records
+}
+
+// recordCacheCheck 记录归档文件的校验结果，status 为空时删除记录，记录失败不影响校验本身
+func (m *Manager) recordCacheCheck(archive, sha256 string, status CacheStatus) {
+	unlock := m.lock(cacheChecksFile)
+	defer unlock()
+
+	records := m.loadCacheChecks()
+	name := filepath.Base(archive)
+	info, err := os.Stat(archive)
+	if status == "" || err != nil {
+		delete(records, name)
+	} else {
+		records[name] = &cacheCheckRecord{
+			Size:      info.Size(),
+			Sha256:    sha256,
+			Status:    status,
+			CheckedAt: time.Now(),
+		}
+	}
+	buf, err := json.Marshal(records)
+	if err != nil {
+		return
+	}
+	_ = os.WriteFile(filepath.Join(m.conf.CachePath, cacheChecksFile), buf, 0o644)
+}
+
+// RemoveCache 删除版本所有平台的归档文件，包括未下载完成的文件，返回删除的文件
+func (m *Manager) RemoveCache(v version.Version) ([]*CacheEntry, error) {
+	entries, err := m.CacheEntries()
+	if err != nil {
+		return nil, err
+	}
+	removed := make([]*CacheEntry, 0)
+	for _, entry := range entries {
+		if !entry.Version.Equal(v) {
+			continue
+		}
+		if err := os.Remove(entry.Path); err != nil {
+			return removed, err
+		}
+		removed = append(removed, entry)
+	}
+	return removed, nil
+}
+
+// PruneCache 清理缓存：过期的 .temp 文件、超过 OlderThan 未使用的归档文件，
+// 以及超出 MaxSize 时最近最少使用的归档文件，返回删除的文件
+func (m *Manager) PruneCache(opts PruneOptions) ([]*CacheEntry, error) {
+	entries, err := m.CacheEntries()
+	if err != nil {
+		return nil, err
+	}
+	if opts.TempAge == 0 {
+		opts.TempAge = time.Hour
+	}
+
+	now := time.Now()
+	installed := m.List(true)
+	isInstalled := func(entry *CacheEntry) bool {
+		if !entry.Host() {
+			return false
+		}
+		for _, v := range installed {
+			if v.Equal(entry.Version) {
+				return true
+			}
+		}
+		return false
+	}
+
+	remove := make([]*CacheEntry, 0)
+	kept := make([]*CacheEntry, 0, len(entries))
+	total := int64(0)
+	for _, entry := range entries {
+		switch {
+		case entry.Partial:
+			// 正在下载的文件会不断更新修改时间，不会被删除
+			if now.Sub(entry.ModTime) > opts.TempAge {
+				remove = append(remove, entry)
+				continue
+			}
+		case opts.KeepInstalled && isInstalled(entry):
+		case opts.OlderThan > 0 && now.Sub(entry.ModTime) > opts.OlderThan:
+			remove = append(remove, entry)
+			continue
+		}
+		kept = append(kept, entry)
+		total += entry.Size
+	}
+
+	// entries 按使用时间从旧到新排序，超出大小时先删除最久未使用的文件
+	if opts.MaxSize > 0 {
+		for _, entry := range kept {
+			if total <= opts.MaxSize {
+				break
+			}
+			if entry.Partial || (opts.KeepInstalled && isInstalled(entry)) {
+				continue
+			}
+			remove = append(remove, entry)
+			total -= entry.Size
+		}
+	}
+
+	if opts.DryRun {
+		return remove, nil
+	}
+	removed := make([]*CacheEntry, 0, len(remove))
+	for _, entry := range remove {
+		if err := os.Remove(entry.Path); err != nil && !os.IsNotExist(err) {
+			return removed, err
+		}
+		removed = append(removed, entry)
+	}
+	return removed, nil
+}
+
+// touch 更新归档文件的使用时间，用于按最近最少使用的顺序清理缓存
+func touch(file string) {
+	now := time.Now()
+	_ = os.Chtimes(file, now, now)
+}
+
+// CacheCheck 归档文件的校验结果
+type CacheCheck struct {
+	Entry  *CacheEntry
+	Status CacheStatus
+}
+
+// VerifyCache 使用 jobs 个协程并行校验缓存目录中的归档文件，结果与 CacheEntries 顺序一致
+func (m *Manager) VerifyCache(jobs int) ([]*CacheCheck, error) {
+	entries, err := m.CacheEntries()
+	if err != nil {
+		return nil, err
+	}
+	if jobs < 1 {
+		jobs = 1
+	}
+
+	checks := make([]*CacheCheck, len(entries))
+	ch := make(chan int)
+	wg := sync.WaitGroup{}
+	for i := 0; i < jobs && i < len(entries); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range ch {
+				entry := entries[idx]
+				checks[idx] = &CacheCheck{Entry: entry, Status: m.CheckCache(entry)}
+				if status := checks[idx].Status; status == CacheOK || status == CacheMismatch {
+					m.recordCacheCheck(entry.Path, m.RemoteInfo(entry.Version).Sha256, status)
+				}
+			}
+		}()
+	}
+	for i := range entries {
+		ch <- i
+	}
+	close(ch)
+	wg.Wait()
+	return checks, nil
+}
+
+// RepairCache 删除校验不通过的归档文件并重新下载
+func (m *Manager) RepairCache(ctx context.Context, entry *CacheEntry, opts FetchOptions) error {
+	info := m.RemoteInfo(entry.Version)
+	if !entry.Host() || info == nil || info.Filename != entry.Name {
+		return versionError(entry.Version, ErrNotFound)
+	}
+	if err := os.Remove(entry.Path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	_, err := m.Fetch(ctx, info, opts)
+	return err
+}
@@ -0,0 +1,107 @@
+package manager
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/serious-snow/govm/pkg/version"
+)
+
+// constraintOps 支持的比较符，较长的在前，避免 >= 被识别为 >
+var constraintOps = []string{">=", "<=", ">", "<", "="}
+
+// IsConstraint 是否为 >=1.21 这样的版本约束
+func IsConstraint(ver string) bool {
+	return strings.ContainsAny(ver, "<>=")
+}
+
+type constraintClause struct {
+	op string
+	v  version.Version
+}
+
+// Constraint 版本约束，如 >=1.21,<1.23，多个条件以逗号或空格分隔，需要同时满足
+type Constraint []constraintClause
+
+// ParseConstraint 解析版本约束，没有比较符的条件视为 =
+func ParseConstraint(expr string) (Constraint, error) {
+	fields := strings.FieldsFunc(expr, func(r rune) bool {
+		return r == ',' || r == ' '
+	})
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("无效的版本约束 %q", expr)
+	}
+	c := make(Constraint, 0, len(fields))
+	for _, field := range fields {
+		op := "="
+		for _, o := range constraintOps {
+			if strings.HasPrefix(field, o) {
+				op = o
+				break
+			}
+		}
+		v := version.New(TrimVersion(strings.TrimPrefix(field, op)))
+		if !v.Valid() {
+			return nil, fmt.Errorf("无效的版本约束 %q", expr)
+		}
+		c = append(c, constraintClause{op: op, v: *v})
+	}
+	return c, nil
+}
+
+// Match 版本是否满足所有条件，条件中没有补丁版本时按次版本比较，如 <=1.22 包含 1.22.5
+func (c Constraint) Match(v version.Version) bool {
+	for _, clause := range c {
+		cmp := v.Compare(clause.v)
+		if clause.v.Patch == nil && !clause.v.RC && !clause.v.Beta {
+			cmp = version.New(v.MinorVersion()).Compare(clause.v)
+		}
+		var ok bool
+		switch clause.op {
+		case ">=":
+			ok = cmp >= 0
+		case "<=":
+			ok = cmp <= 0
+		case ">":
+			ok = cmp > 0
+		case "<":
+			ok = cmp < 0
+		default:
+			ok = cmp == 0
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// ResolveConstraint 将版本约束解析为远程版本列表中满足约束的每个次版本的最新稳定版本，按版本从旧到新排序
+func (m *Manager) ResolveConstraint(expr string) ([]version.Version, error) {
+	c, err := ParseConstraint(expr)
+	if err != nil {
+		return nil, err
+	}
+	newest := map[string]version.Version{}
+	for _, info := range m.remote {
+		if !info.Stable || !c.Match(info.Version) {
+			continue
+		}
+		minor := info.Version.MinorVersion()
+		if v, ok := newest[minor]; !ok || info.Version.Greater(v) {
+			newest[minor] = info.Version
+		}
+	}
+	if len(newest) == 0 {
+		return nil, versionError(expr, ErrNotFound)
+	}
+	result := make([]version.Version, 0, len(newest))
+	for _, v := range newest {
+		result = append(result, v)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Less(result[j])
+	})
+	return result, nil
+}
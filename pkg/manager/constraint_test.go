@@ -0,0 +1,51 @@
+package manager
+
+import (
+	"testing"
+
+	"github.com/serious-snow/govm/pkg/version"
+)
+
+func TestConstraint_Match(t *testing.T) {
+	tests := []struct {
+		expr string
+		v    string
+		want bool
+	}{
+		{">=1.21", "1.21.0", true},
+		{">=1.21", "1.20.14", false},
+		{"<1.23", "1.22.9", true},
+		{"<1.23", "1.23.0", false},
+		{"<=1.22", "1.22.5", true},
+		{">1.22", "1.22.5", false},
+		{">=1.21.3,<1.22", "1.21.2", false},
+		{">=1.21.3 <1.22", "1.21.5", true},
+	}
+	for _, tt := range tests {
+		c, err := ParseConstraint(tt.expr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := c.Match(*version.New(tt.v)); got != tt.want {
+			t.Errorf("%s Match(%s) = %v, want %v", tt.expr, tt.v, got, tt.want)
+		}
+	}
+
+	if _, err := ParseConstraint(">=foo"); err == nil {
+		t.Error("want error for invalid constraint")
+	}
+}
+
+func TestManager_ResolveConstraint(t *testing.T) {
+	m, _, _, _ := newTestManager(t)
+	got, err := m.ResolveConstraint(">=1.21")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].String() != "1.21.5" || got[1].String() != "1.22.1" {
+		t.Errorf("got %v", got)
+	}
+	if _, err := m.ResolveConstraint(">=1.30"); err == nil {
+		t.Error("want error when nothing matches")
+	}
+}
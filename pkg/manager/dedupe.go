@@ -0,0 +1,136 @@
+package manager
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/serious-snow/govm/pkg/utils/path"
+	"github.com/serious-snow/govm/pkg/version"
+)
+
+// ErrDedupeUnsupported 存储或系统不支持硬链接去重
+var ErrDedupeUnsupported = errors.New("当前存储或系统不支持硬链接去重")
+
+// Deduper 支持用硬链接合并不同版本中相同文件的存储
+type Deduper interface {
+	// Dedupe 将版本中与已有内容相同的文件替换为硬链接
+	Dedupe(v version.Version) (DedupeResult, error)
+}
+
+// DedupeResult 去重结果
+type DedupeResult struct {
+	Files int   // 替换为硬链接的文件数
+	Saved int64 // 节省的空间
+}
+
+// Dedupe 将已安装版本中与其他版本相同的文件替换为硬链接
+func (m *Manager) Dedupe(ver string) (DedupeResult, error) {
+	v := version.New(TrimVersion(ver))
+	if !m.IsInstalled(*v) {
+		return DedupeResult{}, versionError(v, ErrNotInstalled)
+	}
+	deduper, ok := m.conf.Store.(Deduper)
+	if !ok {
+		return DedupeResult{}, ErrDedupeUnsupported
+	}
+
+	unlock := m.lock(v.String())
+	defer unlock()
+	return deduper.Dedupe(*v)
+}
+
+// objectsPath 按内容寻址的文件存放于 installPath/.objects/<sha256 前两位>/<sha256>，
+// 各版本中的相同文件都是它的硬链接
+func (s *FileStore) objectsPath() string {
+	return filepath.Join(s.installPath, ".objects")
+}
+
+func (s *FileStore) Dedupe(v version.Version) (DedupeResult, error) {
+	result := DedupeResult{}
+	objects := s.objectsPath()
+	if info, err := os.Stat(s.installPath); err != nil {
+		return result, err
+	} else if _, _, ok := path.LinkInfo(info); !ok {
+		return result, ErrDedupeUnsupported
+	}
+
+	err := filepath.WalkDir(s.GoRoot(v), func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		// 空文件没有必要去重
+		if info.Size() == 0 {
+			return nil
+		}
+
+		sha := fileSha256(name)
+		if sha == "" {
+			return nil
+		}
+		object := filepath.Join(objects, sha[:2], sha)
+		objectInfo, err := os.Stat(object)
+		if os.IsNotExist(err) {
+			if err := path.MakeDir(filepath.Dir(object)); err != nil {
+				return err
+			}
+			// 同时安装多个版本时可能已被其他版本创建
+			if err = os.Link(name, object); !os.IsExist(err) {
+				return err
+			}
+			objectInfo, err = os.Stat(object)
+		}
+		if err != nil {
+			return err
+		}
+		if os.SameFile(info, objectInfo) {
+			return nil
+		}
+
+		// 先创建临时链接再替换，中途失败不会丢失文件
+		temp := name + ".govm-link"
+		if err := os.Link(object, temp); err != nil {
+			return err
+		}
+		if err := os.Rename(temp, name); err != nil {
+			_ = os.Remove(temp)
+			return err
+		}
+		result.Files++
+		result.Saved += info.Size()
+		return nil
+	})
+	return result, err
+}
+
+// pruneObjects 删除只剩自身一个链接的文件，即已经没有版本使用的文件
+func (s *FileStore) pruneObjects() error {
+	objects := s.objectsPath()
+	if !path.PathIsExisted(objects) {
+		return nil
+	}
+	return filepath.WalkDir(objects, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if _, nlink, ok := path.LinkInfo(info); ok && nlink <= 1 {
+			return os.Remove(name)
+		}
+		return nil
+	})
+}
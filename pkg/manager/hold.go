@@ -0,0 +1,68 @@
+package manager
+
+import (
+	"path"
+	"strings"
+	"time"
+
+	"github.com/serious-snow/govm/pkg/version"
+)
+
+// Hold 版本保留记录
+type Hold struct {
+	Pattern   string     `json:"pattern"` // 版本或模式，如 1.21.5、1.21.*
+	Reason    string     `json:"reason,omitempty"`
+	Author    string     `json:"author,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+// HoldOptions 保留选项
+type HoldOptions struct {
+	Reason    string
+	Author    string
+	ExpiresAt *time.Time
+}
+
+// IsPattern 是否为模式保留
+func (h Hold) IsPattern() bool {
+	return IsPattern(h.Pattern)
+}
+
+// Expired 是否已过期
+func (h Hold) Expired(now time.Time) bool {
+	return h.ExpiresAt != nil && !now.Before(*h.ExpiresAt)
+}
+
+// Match 版本是否匹配该保留记录，不考虑过期
+func (h Hold) Match(v version.Version) bool {
+	return MatchPattern(h.Pattern, v)
+}
+
+// IsPattern 版本是否包含通配符，如 1.21.*
+func IsPattern(ver string) bool {
+	return strings.ContainsAny(ver, "*?[")
+}
+
+// MatchPattern 版本是否匹配具体版本或 1.21.* 这样的模式
+func MatchPattern(pattern string, v version.Version) bool {
+	if !IsPattern(pattern) {
+		return version.New(pattern).Equal(v)
+	}
+	ok, _ := path.Match(pattern, v.String())
+	return ok
+}
+
+// FindHold 查找匹配版本且未过期的保留记录，找不到时返回 nil
+func FindHold(holds []Hold, v version.Version) *Hold {
+	now := time.Now()
+	for i := range holds {
+		if holds[i].Expired(now) {
+			continue
+		}
+		if holds[i].Match(v) {
+			return &holds[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package manager
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"time"
+
+	"github.com/serious-snow/govm/pkg/version"
+)
+
+// upgradeJournal 升级日志，升级过程中中断时用于回滚或继续
+type upgradeJournal struct {
+	Previous  string         `json:"previous"`  // 升级前激活的版本
+	KeepOld   bool           `json:"keepOld"`   // 是否保留旧版本
+	Committed bool           `json:"committed"` // 所有版本已安装，只剩清理旧版本
+	CreatedAt time.Time      `json:"createdAt"`
+	Pid       int            `json:"pid"` // 执行升级的进程，仍在运行时其他进程不处理该日志
+	Steps     []*journalStep `json:"steps"`
+}
+
+type journalStep struct {
+	From         string `json:"from"`
+	To           string `json:"to"`
+	PreInstalled bool   `json:"preInstalled"` // 升级前目标版本已安装，回滚时不删除
+	Installing   bool   `json:"installing"`   // 已开始安装目标版本
+	Detached     bool   `json:"detached"`     // 旧版本已被移走
+}
+
+func (m *Manager) saveJournal(j *upgradeJournal) error {
+	if m.conf.JournalPath == "" {
+		return nil
+	}
+	buf, err := json.Marshal(j)
+	if err != nil {
+		return err
+	}
+	// 先写临时文件再重命名，避免中断时留下损坏的日志
+	temp := m.conf.JournalPath + ".temp"
+	if err := os.WriteFile(temp, buf, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(temp, m.conf.JournalPath)
+}
+
+func (m *Manager) loadJournal() (*upgradeJournal, error) {
+	if m.conf.JournalPath == "" {
+		return nil, nil
+	}
+	buf, err := os.ReadFile(m.conf.JournalPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	j := &upgradeJournal{}
+	if err := json.Unmarshal(buf, j); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+func (m *Manager) removeJournal() error {
+	if m.conf.JournalPath == "" {
+		return nil
+	}
+	if err := os.Remove(m.conf.JournalPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// commit 删除被移走的旧版本并取消保留，返回卸载的版本
+func (m *Manager) commit(j *upgradeJournal) ([]string, error) {
+	j.Committed = true
+	if err := m.saveJournal(j); err != nil {
+		return nil, err
+	}
+
+	removed := make([]string, 0)
+	var errs []error
+	for _, s := range j.Steps {
+		if !s.Detached {
+			continue
+		}
+		if err := m.conf.Store.Purge(*version.New(s.From)); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := m.Unhold(s.From); err != nil {
+			errs = append(errs, err)
+		}
+		removed = append(removed, s.From)
+	}
+	if len(errs) != 0 {
+		return removed, errors.Join(errs...)
+	}
+	return removed, m.removeJournal()
+}
+
+// rollback 恢复被移走的旧版本，删除本次新安装的版本，并重新激活升级前的版本
+func (m *Manager) rollback(j *upgradeJournal) error {
+	var errs []error
+	for i := len(j.Steps) - 1; i >= 0; i-- {
+		s := j.Steps[i]
+		if s.Detached {
+			if err := m.conf.Store.Restore(*version.New(s.From)); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		if s.Installing && !s.PreInstalled {
+			if err := m.conf.Store.Remove(*version.New(s.To)); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	if j.Previous != "" {
+		if err := m.Use(j.Previous); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) != 0 {
+		return errors.Join(errs...)
+	}
+	return m.removeJournal()
+}
+
+// running 日志所属的升级是否正在其他进程中执行，旧版本写入的日志没有 Pid，视为已中断
+func (j *upgradeJournal) running() bool {
+	return j.Pid != 0 && j.Pid != os.Getpid() && processAlive(j.Pid)
+}
+
+// Recover 处理上次中断的升级，已完成安装的继续清理旧版本，否则回滚，
+// 没有中断的升级或升级正在其他进程中执行时返回 false
+func (m *Manager) Recover() (bool, error) {
+	j, err := m.loadJournal()
+	if err != nil || j == nil || j.running() {
+		return false, err
+	}
+	if j.Committed {
+		_, err = m.commit(j)
+		return true, err
+	}
+	return true, m.rollback(j)
+}
@@ -0,0 +1,47 @@
+package manager
+
+import (
+	"os"
+)
+
+// Linker 负责激活版本
+type Linker interface {
+	// Link 将激活路径指向 goRoot
+	Link(goRoot string) error
+	// Unlink 取消激活
+	Unlink() error
+	// Target 当前激活的 goRoot，未激活时返回 os.ErrNotExist
+	Target() (string, error)
+}
+
+// SymlinkLinker 通过软连接激活版本
+type SymlinkLinker struct {
+	linkPath string
+	symlink  func(oldname, newname string) error
+}
+
+// NewSymlinkLinker symlink 为空时使用 os.Symlink
+func NewSymlinkLinker(linkPath string, symlink func(oldname, newname string) error) *SymlinkLinker {
+	if symlink == nil {
+		symlink = os.Symlink
+	}
+	return &SymlinkLinker{linkPath: linkPath, symlink: symlink}
+}
+
+func (l *SymlinkLinker) Link(goRoot string) error {
+	if err := l.Unlink(); err != nil {
+		return err
+	}
+	return l.symlink(goRoot, l.linkPath)
+}
+
+func (l *SymlinkLinker) Unlink() error {
+	if err := os.Remove(l.linkPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (l *SymlinkLinker) Target() (string, error) {
+	return os.Readlink(l.linkPath)
+}
@@ -0,0 +1,450 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/serious-snow/govm/pkg/utils"
+	"github.com/serious-snow/govm/pkg/utils/path"
+	"github.com/serious-snow/govm/pkg/version"
+)
+
+// Config 管理器配置
+type Config struct {
+	CachePath string // 归档文件缓存目录
+	// JournalPath 升级日志路径，为空时不记录，中断的升级无法恢复
+	JournalPath string
+	Source      Source
+	Store       Store
+	Linker      Linker
+	// Logf 输出下载、卸载等过程信息，为空时不输出
+	Logf func(format string, a ...any)
+	// GovmVersion 记录到版本来源信息中的 govm 版本
+	GovmVersion string
+}
+
+// Manager go 版本管理器
+type Manager struct {
+	conf   Config
+	remote []*GoVersionInfo
+	// locks 安装目录和归档文件的锁，同时安装多个版本时保证同一目录的解压、同一文件的下载串行执行
+	locks sync.Map
+}
+
+func New(conf Config) *Manager {
+	if conf.Logf == nil {
+		conf.Logf = func(string, ...any) {}
+	}
+	return &Manager{conf: conf}
+}
+
+// Remote 远程版本列表，按版本从新到旧排序
+func (m *Manager) Remote() []*GoVersionInfo {
+	return m.remote
+}
+
+// SetRemote 设置远程版本列表，一般来自本地缓存
+func (m *Manager) SetRemote(list []*GoVersionInfo) {
+	m.remote = list
+}
+
+// Refresh 从远程版本源更新版本列表，已知的发布日期沿用之前的版本列表，只查询新文件的发布日期
+func (m *Manager) Refresh(ctx context.Context) ([]*GoVersionInfo, error) {
+	list, err := m.conf.Source.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]*time.Time, len(m.remote))
+	for _, info := range m.remote {
+		if info.ReleasedAt != nil {
+			known[info.Filename] = info.ReleasedAt
+		}
+	}
+	missing := make([]string, 0)
+	for _, info := range list {
+		if info.ReleasedAt != nil {
+			continue
+		}
+		if t, ok := known[info.Filename]; ok {
+			info.ReleasedAt = t
+			continue
+		}
+		missing = append(missing, info.Filename)
+	}
+	if ds, ok := m.conf.Source.(ReleaseDateSource); ok && len(missing) != 0 {
+		// 查询失败时不影响版本列表，下次更新时会重新查询
+		dates, _ := ds.ReleaseDates(ctx, missing)
+		for _, info := range list {
+			if t, ok := dates[info.Filename]; ok {
+				info.ReleasedAt = &t
+			}
+		}
+	}
+
+	m.remote = list
+	return list, nil
+}
+
+// RemoteInfo 查找远程版本信息，找不到时返回 nil
+func (m *Manager) RemoteInfo(v version.Version) *GoVersionInfo {
+	for _, info := range m.remote {
+		if v.Equal(info.Version) {
+			return info
+		}
+	}
+	return nil
+}
+
+// IsCached 安装包是否已下载到缓存目录
+func (m *Manager) IsCached(v version.Version) bool {
+	info := m.RemoteInfo(v)
+	return info != nil && path.FileIsExisted(filepath.Join(m.conf.CachePath, info.Filename))
+}
+
+// List 版本列表，installed 为 true 时只返回已安装的版本
+func (m *Manager) List(installed bool) []*version.Version {
+	if installed {
+		versions, err := m.conf.Store.Versions()
+		if err != nil {
+			return nil
+		}
+		return versions
+	}
+	versions := make([]*version.Version, 0, len(m.remote))
+	for _, info := range m.remote {
+		versions = append(versions, &info.Version)
+	}
+	return versions
+}
+
+func (m *Manager) IsInstalled(v version.Version) bool {
+	for _, installed := range m.List(true) {
+		if installed.Equal(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// Meta 安装时记录的版本来源信息，旧版本 govm 安装的版本没有记录时返回 nil
+func (m *Manager) Meta(v version.Version) *InstallMeta {
+	meta, _ := m.conf.Store.Meta(v)
+	return meta
+}
+
+// GoRoot 指定版本的 GOROOT
+func (m *Manager) GoRoot(v version.Version) string {
+	return m.conf.Store.GoRoot(v)
+}
+
+// IsStable 是否为稳定版本，不在远程列表中时根据是否为 rc、beta 判断
+func (m *Manager) IsStable(v version.Version) bool {
+	if info := m.RemoteInfo(v); info != nil {
+		return info.Stable
+	}
+	return !v.RC && !v.Beta
+}
+
+// Alias 解析版本别名，remote 为 true 时从远程列表中选择，否则从已安装的版本中选择
+func (m *Manager) Alias(name string, remote bool) (version.Version, error) {
+	if !IsAlias(name) {
+		return version.Version{}, versionError(name, ErrNotFound)
+	}
+	versions := m.List(!remote)
+	version.SortV(versions).Sort()
+	for i := len(versions) - 1; i >= 0; i-- {
+		if name == AliasLatest || m.IsStable(*versions[i]) {
+			return *versions[i], nil
+		}
+	}
+	if remote {
+		return version.Version{}, versionError(name, ErrNotFound)
+	}
+	return version.Version{}, versionError(name, ErrNotInstalled)
+}
+
+// Resolve 解析版本，输入为次版本（如 1.21）时选择已安装的最新补丁版本，
+// remote 为 true 且没有已安装的补丁版本时，选择远程列表中的最新补丁版本，
+// 也支持 latest、stable 别名
+func (m *Manager) Resolve(ver string, remote bool) (version.Version, error) {
+	if IsAlias(ver) {
+		return m.Alias(ver, remote)
+	}
+	v := version.New(TrimVersion(ver))
+
+	if m.IsInstalled(*v) || (remote && m.RemoteInfo(*v) != nil) {
+		return *v, nil
+	}
+
+	minorVersion := v.MinorVersion()
+	if v.String() == minorVersion {
+		if vls := GetMinorGroup(m.List(true))[minorVersion]; len(vls) != 0 {
+			return *vls[0], nil
+		}
+		if remote {
+			if vls := GetMinorGroup(m.List(false))[minorVersion]; len(vls) != 0 {
+				return *vls[0], nil
+			}
+		}
+	}
+
+	if remote {
+		return version.Version{}, versionError(v, ErrNotFound)
+	}
+	return version.Version{}, versionError(v, ErrNotInstalled)
+}
+
+// ResolveAtLeast 解析项目要求的版本，如 go.mod 中的 go 1.21.3 表示最低版本，
+// 没有安装该版本时选择同一次版本下不低于该版本的最新已安装版本
+func (m *Manager) ResolveAtLeast(ver string) (version.Version, error) {
+	if v, err := m.Resolve(ver, false); err == nil {
+		return v, nil
+	}
+
+	v := version.New(TrimVersion(ver))
+	if vls := GetMinorGroup(m.List(true))[v.MinorVersion()]; len(vls) != 0 && !vls[0].Less(*v) {
+		return *vls[0], nil
+	}
+	return version.Version{}, versionError(v, ErrNotInstalled)
+}
+
+// Install 下载并安装版本，已缓存且校验通过的归档文件不会重新下载
+func (m *Manager) Install(ctx context.Context, ver string, opts InstallOptions) error {
+	v := version.New(TrimVersion(ver))
+
+	unlock := m.lock(v.String())
+	defer unlock()
+
+	if !opts.Force && m.IsInstalled(*v) {
+		return versionError(v, ErrAlreadyInstalled)
+	}
+
+	info := m.RemoteInfo(*v)
+	if info == nil {
+		return ErrNotFound
+	}
+
+	archive, err := m.Fetch(ctx, info, FetchOptions{IgnoreSha256: opts.IgnoreSha256, Quiet: opts.Quiet})
+	if err != nil {
+		return err
+	}
+
+	if err := m.conf.Store.Install(*v, archive, opts.Slim); err != nil {
+		return fmt.Errorf("解压失败:%w", err)
+	}
+
+	// 来源信息和文件清单只用于 info、verify、repair，记录失败不影响安装
+	meta := &InstallMeta{
+		Version:      v.String(),
+		Filename:     info.Filename,
+		URL:          m.conf.Source.URL(info),
+		Sha256:       info.Sha256,
+		IgnoreSha256: opts.IgnoreSha256,
+		InstalledAt:  time.Now(),
+		GovmVersion:  m.conf.GovmVersion,
+		Slim:         opts.Slim,
+	}
+	if err := m.conf.Store.SaveMeta(*v, meta); err != nil {
+		m.conf.Logf("%s 记录安装信息失败：%s\n", v, err)
+	}
+	manifest, err := BuildManifest(*v, archive, opts.Slim)
+	if err == nil {
+		err = m.conf.Store.SaveManifest(*v, manifest)
+	}
+	if err != nil {
+		m.conf.Logf("%s 记录文件清单失败：%s\n", v, err)
+	}
+	return nil
+}
+
+// Fetch 下载并校验归档文件到缓存目录，不解压，已缓存且校验通过时不会重新下载，返回归档文件路径
+func (m *Manager) Fetch(ctx context.Context, info *GoVersionInfo, opts FetchOptions) (string, error) {
+	unlock := m.lock(info.Filename)
+	defer unlock()
+
+	sha := info.Sha256
+	if opts.IgnoreSha256 {
+		sha = ""
+	}
+
+	archive := filepath.Join(m.conf.CachePath, info.Filename)
+	if path.FileIsExisted(archive) {
+		if sha == "" {
+			touch(archive)
+			return archive, nil
+		}
+		if utils.CheckSha256(archive, sha) {
+			touch(archive)
+			m.recordCacheCheck(archive, sha, CacheOK)
+			return archive, nil
+		}
+		if err := os.Remove(archive); err != nil {
+			return "", fmt.Errorf("删除损坏的缓存文件失败: %w", err)
+		}
+	}
+
+	if !opts.Quiet {
+		m.conf.Logf("开始下载：%s\n", info.Version)
+	}
+	if err := m.conf.Source.Download(ctx, info, m.conf.CachePath, sha); err != nil {
+		return "", err
+	}
+	// 不校验时下载的文件没有校验记录
+	if sha == "" {
+		m.recordCacheCheck(archive, "", "")
+	} else {
+		m.recordCacheCheck(archive, sha, CacheOK)
+	}
+	return archive, nil
+}
+
+// RemotePlatform 其他平台的远程版本列表，版本源不支持时返回 ErrNotFound
+func (m *Manager) RemotePlatform(ctx context.Context, goos, goarch string) ([]*GoVersionInfo, error) {
+	source, ok := m.conf.Source.(PlatformSource)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return source.ListPlatform(ctx, goos, goarch)
+}
+
+// lock 锁定安装目录或归档文件，返回解锁函数
+func (m *Manager) lock(key string) func() {
+	mu, _ := m.locks.LoadOrStore(key, &sync.Mutex{})
+	mu.(*sync.Mutex).Lock()
+	return mu.(*sync.Mutex).Unlock
+}
+
+// Use 激活已安装的版本
+func (m *Manager) Use(ver string) error {
+	v := version.New(TrimVersion(ver))
+	if !m.IsInstalled(*v) {
+		return versionError(v, ErrNotInstalled)
+	}
+
+	goRoot := m.conf.Store.GoRoot(*v)
+	if !path.PathIsExisted(goRoot) {
+		return ErrBrokenInstall
+	}
+
+	return m.conf.Linker.Link(goRoot)
+}
+
+// Unuse 取消激活当前版本
+func (m *Manager) Unuse() error {
+	if _, err := m.Current(); err != nil {
+		return err
+	}
+	return m.conf.Linker.Unlink()
+}
+
+// Current 当前激活的版本，没有激活的版本时返回 ErrNoActive
+func (m *Manager) Current() (version.Version, error) {
+	target, err := m.conf.Linker.Target()
+	if err != nil {
+		return version.Version{}, ErrNoActive
+	}
+	target = filepath.Clean(target)
+	for _, v := range m.List(true) {
+		if filepath.Clean(m.conf.Store.GoRoot(*v)) == target {
+			return *v, nil
+		}
+	}
+	return version.Version{}, ErrNoActive
+}
+
+// Uninstall 卸载版本，同时取消保留，卸载当前激活的版本时先取消激活，避免 go 链接指向被删除的目录
+func (m *Manager) Uninstall(ver string) error {
+	v := version.New(TrimVersion(ver))
+	if !m.IsInstalled(*v) {
+		return versionError(v, ErrNotInstalled)
+	}
+
+	if current, err := m.Current(); err == nil && current.Equal(*v) {
+		if err := m.conf.Linker.Unlink(); err != nil {
+			return err
+		}
+	}
+
+	if err := m.conf.Store.Remove(*v); err != nil {
+		return err
+	}
+
+	return m.Unhold(v.String())
+}
+
+// Holds 保留记录列表，包含已过期的记录
+func (m *Manager) Holds() []Hold {
+	holds, _ := m.conf.Store.Holds()
+	return holds
+}
+
+// HoldOf 匹配版本且未过期的保留记录，没有时返回 nil
+func (m *Manager) HoldOf(v version.Version) *Hold {
+	return FindHold(m.Holds(), v)
+}
+
+func (m *Manager) IsHeld(ver string) bool {
+	return m.HoldOf(*version.New(TrimVersion(ver))) != nil
+}
+
+// Hold 保留版本，被保留的版本不会被升级，pattern 可以是具体版本或 1.21.* 这样的模式，
+// 已存在的记录会被覆盖
+func (m *Manager) Hold(pattern string, opts HoldOptions) error {
+	pattern = TrimVersion(pattern)
+	if !IsPattern(pattern) {
+		v := version.New(pattern)
+		if !m.IsInstalled(*v) {
+			return versionError(pattern, ErrNotInstalled)
+		}
+		pattern = v.String()
+	}
+
+	hold := Hold{
+		Pattern:   pattern,
+		Reason:    opts.Reason,
+		Author:    opts.Author,
+		CreatedAt: time.Now(),
+		ExpiresAt: opts.ExpiresAt,
+	}
+
+	holds := m.Holds()
+	for i := range holds {
+		if holds[i].Pattern == pattern {
+			holds[i] = hold
+			return m.conf.Store.SaveHolds(holds)
+		}
+	}
+	return m.conf.Store.SaveHolds(append(holds, hold))
+}
+
+// Unhold 取消保留，pattern 需与保留时一致
+func (m *Manager) Unhold(pattern string) error {
+	pattern = TrimVersion(pattern)
+
+	holds := m.Holds()
+	newHolds := make([]Hold, 0, len(holds))
+	for _, h := range holds {
+		if h.Pattern == pattern {
+			continue
+		}
+		newHolds = append(newHolds, h)
+	}
+	if len(newHolds) == len(holds) {
+		return nil
+	}
+	return m.conf.Store.SaveHolds(newHolds)
+}
+
+// GetMinorGroup 按次版本分组，如 1.18 -> [1.18.10, 1.18.9]
+func GetMinorGroup(list []*version.Version) map[string][]*version.Version {
+	m := make(map[string][]*version.Version)
+	for _, v := range list {
+		m[v.MinorVersion()] = append(m[v.MinorVersion()], v)
+	}
+	return m
+}
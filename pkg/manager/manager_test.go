@@ -0,0 +1,805 @@
+package manager
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/serious-snow/govm/pkg/version"
+)
+
+type fakeSource struct {
+	list        []*GoVersionInfo
+	downloads   []string
+	fail        map[string]bool
+	dateQueries [][]string
+}
+
+func (s *fakeSource) List(context.Context) ([]*GoVersionInfo, error) {
+	// 返回副本，与远程版本源一样每次都是新的版本信息
+	list := make([]*GoVersionInfo, 0, len(s.list))
+	for _, info := range s.list {
+		info := *info
+		list = append(list, &info)
+	}
+	return list, nil
+}
+
+func (s *fakeSource) ReleaseDates(_ context.Context, filenames []string) (map[string]time.Time, error) {
+	s.dateQueries = append(s.dateQueries, filenames)
+	dates := make(map[string]time.Time, len(filenames))
+	for _, name := range filenames {
+		dates[name] = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	return dates, nil
+}
+
+func (s *fakeSource) URL(info *GoVersionInfo) string {
+	return "https://example.com/" + info.Filename
+}
+
+func (s *fakeSource) Download(_ context.Context, info *GoVersionInfo, dir, _ string) error {
+	if s.fail[info.Version.String()] {
+		return errors.New("download failed")
+	}
+	s.downloads = append(s.downloads, info.Version.String())
+	return os.WriteFile(filepath.Join(dir, info.Filename), []byte(info.Filename), 0o644)
+}
+
+type fakeStore struct {
+	root      string
+	versions  map[string]bool
+	detached  map[string]bool
+	holds     []Hold
+	manifests map[string]*Manifest
+	metas     map[string]*InstallMeta
+	detachErr error // 移走版本后返回的错误，模拟移走后中断
+}
+
+func (s *fakeStore) Versions() ([]*version.Version, error) {
+	list := make([]*version.Version, 0, len(s.versions))
+	for v := range s.versions {
+		list = append(list, version.New(v))
+	}
+	version.SortV(list).Reverse()
+	return list, nil
+}
+
+func (s *fakeStore) Install(v version.Version, _ string, _ *SlimProfile) error {
+	s.versions[v.String()] = true
+	return os.MkdirAll(s.GoRoot(v), 0o755)
+}
+
+func (s *fakeStore) Remove(v version.Version) error {
+	delete(s.versions, v.String())
+	return nil
+}
+
+func (s *fakeStore) Detach(v version.Version) error {
+	delete(s.versions, v.String())
+	s.detached[v.String()] = true
+	return s.detachErr
+}
+
+func (s *fakeStore) Restore(v version.Version) error {
+	if s.detached[v.String()] {
+		delete(s.detached, v.String())
+		s.versions[v.String()] = true
+	}
+	return nil
+}
+
+func (s *fakeStore) Purge(v version.Version) error {
+	delete(s.detached, v.String())
+	return nil
+}
+
+func (s *fakeStore) GoRoot(v version.Version) string {
+	return filepath.Join(s.root, v.String(), "go")
+}
+
+func (s *fakeStore) Holds() ([]Hold, error) {
+	return s.holds, nil
+}
+
+func (s *fakeStore) SaveHolds(holds []Hold) error {
+	s.holds = holds
+	return nil
+}
+
+func (s *fakeStore) Manifest(v version.Version) (*Manifest, error) {
+	return s.manifests[v.String()], nil
+}
+
+func (s *fakeStore) SaveManifest(v version.Version, manifest *Manifest) error {
+	s.manifests[v.String()] = manifest
+	return nil
+}
+
+func (s *fakeStore) Meta(v version.Version) (*InstallMeta, error) {
+	return s.metas[v.String()], nil
+}
+
+func (s *fakeStore) SaveMeta(v version.Version, meta *InstallMeta) error {
+	s.metas[v.String()] = meta
+	return nil
+}
+
+type fakeLinker struct {
+	target string
+}
+
+func (l *fakeLinker) Link(goRoot string) error {
+	l.target = goRoot
+	return nil
+}
+
+func (l *fakeLinker) Unlink() error {
+	l.target = ""
+	return nil
+}
+
+func (l *fakeLinker) Target() (string, error) {
+	if l.target == "" {
+		return "", os.ErrNotExist
+	}
+	return l.target, nil
+}
+
+func newTestManager(t *testing.T) (*Manager, *fakeSource, *fakeStore, *fakeLinker) {
+	t.Helper()
+	dir := t.TempDir()
+	source := &fakeSource{}
+	for _, v := range []string{"1.22.1", "1.22.0", "1.21.5", "1.21.4"} {
+		source.list = append(source.list, &GoVersionInfo{
+			Filename: "go" + v + ".tar.gz",
+			Version:  *version.New(v),
+			Stable:   true,
+		})
+	}
+	store := &fakeStore{
+		root:      filepath.Join(dir, "install"),
+		versions:  map[string]bool{},
+		detached:  map[string]bool{},
+		manifests: map[string]*Manifest{},
+		metas:     map[string]*InstallMeta{},
+	}
+	linker := &fakeLinker{}
+	m := New(Config{
+		CachePath:   dir,
+		JournalPath: filepath.Join(dir, "upgrade.json"),
+		Source:      source,
+		Store:       store,
+		Linker:      linker,
+	})
+	if _, err := m.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return m, source, store, linker
+}
+
+func TestManager_RefreshReleaseDates(t *testing.T) {
+	m, source, _, _ := newTestManager(t)
+	source.list = append([]*GoVersionInfo{{
+		Filename: "go1.22.2.tar.gz",
+		Version:  *version.New("1.22.2"),
+		Stable:   true,
+	}}, source.list...)
+
+	list, err := m.Refresh(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 已知的发布日期沿用之前的版本列表，只查询新版本
+	if len(source.dateQueries) != 2 || len(source.dateQueries[1]) != 1 || source.dateQueries[1][0] != "go1.22.2.tar.gz" {
+		t.Fatalf("unexpected queries %v", source.dateQueries)
+	}
+	for _, info := range list {
+		if info.ReleasedAt == nil {
+			t.Errorf("%s has no release date", info.Version)
+		}
+	}
+}
+
+func TestManager_CachedStatus(t *testing.T) {
+	m, _, _, _ := newTestManager(t)
+	info := m.RemoteInfo(*version.New("1.21.5"))
+	sum := sha256.Sum256([]byte(info.Filename))
+	info.Sha256 = hex.EncodeToString(sum[:])
+	entry := &CacheEntry{
+		Name:     info.Filename,
+		Path:     filepath.Join(m.conf.CachePath, info.Filename),
+		Version:  info.Version,
+		Platform: runtime.GOOS + "/" + runtime.GOARCH,
+		Size:     int64(len(info.Filename)),
+	}
+	if status := m.CachedStatus(entry); status != CacheUnverified {
+		t.Fatalf("want unverified before download, got %s", status)
+	}
+
+	// 下载时校验通过的文件记录为已校验
+	if _, err := m.Fetch(context.Background(), info, FetchOptions{Quiet: true}); err != nil {
+		t.Fatal(err)
+	}
+	if status := m.CachedStatus(entry); status != CacheOK {
+		t.Fatalf("want ok after download, got %s", status)
+	}
+
+	// 文件在校验后发生变化时记录失效
+	entry.Size++
+	if status := m.CachedStatus(entry); status != CacheUnverified {
+		t.Fatalf("want unverified after change, got %s", status)
+	}
+}
+
+func TestManager_InstallUseCurrent(t *testing.T) {
+	m, source, _, _ := newTestManager(t)
+	ctx := context.Background()
+
+	if err := m.Install(ctx, "go1.21.4", InstallOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Install(ctx, "1.21.4", InstallOptions{}); !errors.Is(err, ErrAlreadyInstalled) {
+		t.Fatalf("want ErrAlreadyInstalled, got %v", err)
+	}
+	if err := m.Install(ctx, "1.20.0", InstallOptions{}); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("want ErrNotFound, got %v", err)
+	}
+	if len(source.downloads) != 1 {
+		t.Fatalf("want 1 download, got %v", source.downloads)
+	}
+	if meta := m.Meta(*version.New("1.21.4")); meta == nil || meta.URL != "https://example.com/go1.21.4.tar.gz" {
+		t.Fatalf("meta = %+v", meta)
+	}
+
+	if _, err := m.Current(); !errors.Is(err, ErrNoActive) {
+		t.Fatalf("want ErrNoActive, got %v", err)
+	}
+	if err := m.Use("1.21.4"); err != nil {
+		t.Fatal(err)
+	}
+	current, err := m.Current()
+	if err != nil || current.String() != "1.21.4" {
+		t.Fatalf("want 1.21.4, got %v %v", current, err)
+	}
+	if err := m.Use("1.22.0"); !errors.Is(err, ErrNotInstalled) {
+		t.Fatalf("want ErrNotInstalled, got %v", err)
+	}
+}
+
+func TestManager_Resolve(t *testing.T) {
+	m, _, _, _ := newTestManager(t)
+	if err := m.Install(context.Background(), "1.21.4", InstallOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	m.SetRemote(append([]*GoVersionInfo{{
+		Filename: "go1.23rc1.tar.gz",
+		Version:  *version.New("1.23rc1"),
+	}}, m.Remote()...))
+
+	tests := []struct {
+		in     string
+		remote bool
+		want   string
+	}{
+		{"1.21", false, "1.21.4"},
+		{"1.21", true, "1.21.4"},
+		{"1.22", true, "1.22.1"},
+		{"1.21.5", true, "1.21.5"},
+		{"latest", true, "1.23rc1"},
+		{"stable", true, "1.22.1"},
+		{"latest", false, "1.21.4"},
+	}
+	for _, tt := range tests {
+		got, err := m.Resolve(tt.in, tt.remote)
+		if err != nil || got.String() != tt.want {
+			t.Errorf("Resolve(%q, %v) = %v, %v, want %s", tt.in, tt.remote, got, err, tt.want)
+		}
+	}
+
+	if _, err := m.Resolve("1.22", false); !errors.Is(err, ErrNotInstalled) {
+		t.Errorf("want ErrNotInstalled, got %v", err)
+	}
+}
+
+func TestManager_UpgradeHold(t *testing.T) {
+	m, _, store, _ := newTestManager(t)
+	ctx := context.Background()
+	for _, v := range []string{"1.21.4", "1.22.0"} {
+		if err := m.Install(ctx, v, InstallOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := m.Use("1.21.4"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Hold("1.22.0", HoldOptions{Reason: "migration"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Upgrade(ctx, UpgradeOptions{}, "1.22.0"); !errors.Is(err, ErrHeld) {
+		t.Fatalf("want ErrHeld, got %v", err)
+	}
+
+	result, err := m.Upgrade(ctx, UpgradeOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Installed != 1 || result.Uninstalled != 1 || result.Ignored != 1 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	if !store.versions["1.21.5"] || store.versions["1.21.4"] || !store.versions["1.22.0"] {
+		t.Fatalf("unexpected versions %v", store.versions)
+	}
+	current, _ := m.Current()
+	if current.String() != "1.21.5" {
+		t.Fatalf("want active 1.21.5, got %v", current)
+	}
+
+	if err := m.Unhold("1.22.0"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Uninstall("1.22.0"); err != nil {
+		t.Fatal(err)
+	}
+	if len(m.List(true)) != 1 {
+		t.Fatalf("unexpected installed %v", m.List(true))
+	}
+}
+
+func TestManager_UninstallActive(t *testing.T) {
+	m, _, _, linker := newTestManager(t)
+	if err := m.Install(context.Background(), "1.21.4", InstallOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Use("1.21.4"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Uninstall("1.21.4"); err != nil {
+		t.Fatal(err)
+	}
+	if linker.target != "" {
+		t.Fatalf("want link removed, got %q", linker.target)
+	}
+}
+
+func TestManager_PlanUpgradeMinor(t *testing.T) {
+	m, _, store, _ := newTestManager(t)
+	ctx := context.Background()
+	if err := m.Install(ctx, "1.21.4", InstallOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	steps, err := m.PlanUpgrade(UpgradeOptions{Minor: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(steps) != 1 || steps[0].To.String() != "1.22.1" {
+		t.Fatalf("unexpected steps %+v", steps)
+	}
+
+	result := m.ApplyUpgrade(ctx, steps, UpgradeOptions{Minor: true, KeepOld: true})
+	if result.Installed != 1 || result.Uninstalled != 0 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	if !store.versions["1.21.4"] || !store.versions["1.22.1"] {
+		t.Fatalf("unexpected versions %v", store.versions)
+	}
+}
+
+func TestManager_PlanUpgradeMinorKeepsOtherMinors(t *testing.T) {
+	m, _, _, _ := newTestManager(t)
+	ctx := context.Background()
+	for _, v := range []string{"1.21.4", "1.22.0"} {
+		if err := m.Install(ctx, v, InstallOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m.remote = append([]*GoVersionInfo{{
+		Filename: "go1.23.0.tar.gz",
+		Version:  *version.New("1.23.0"),
+		Stable:   true,
+	}}, m.remote...)
+
+	// 只有最新的次版本升级到 1.23.0，1.21 只升级补丁版本
+	steps, err := m.PlanUpgrade(UpgradeOptions{Minor: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	targets := map[string]string{}
+	for _, step := range steps {
+		targets[step.From.String()] = step.To.String()
+	}
+	if len(targets) != 2 || targets["1.22.0"] != "1.23.0" || targets["1.21.4"] != "1.21.5" {
+		t.Fatalf("unexpected steps %v", targets)
+	}
+
+	// 指定的版本升级到最新的次版本
+	steps, err = m.PlanUpgrade(UpgradeOptions{Minor: true}, "1.21.4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(steps) != 1 || steps[0].To.String() != "1.23.0" {
+		t.Fatalf("unexpected steps %+v", steps)
+	}
+}
+
+func TestManager_UpgradeRollback(t *testing.T) {
+	m, source, store, _ := newTestManager(t)
+	ctx := context.Background()
+	for _, v := range []string{"1.21.4", "1.22.0"} {
+		if err := m.Install(ctx, v, InstallOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := m.Use("1.22.0"); err != nil {
+		t.Fatal(err)
+	}
+	source.fail = map[string]bool{"1.21.5": true}
+
+	result, err := m.Upgrade(ctx, UpgradeOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.RolledBack || len(result.Errors) != 1 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	if !store.versions["1.21.4"] || !store.versions["1.22.0"] || store.versions["1.22.1"] || len(store.detached) != 0 {
+		t.Fatalf("unexpected versions %v, detached %v", store.versions, store.detached)
+	}
+	current, _ := m.Current()
+	if current.String() != "1.22.0" {
+		t.Fatalf("want active 1.22.0, got %v", current)
+	}
+	if recovered, err := m.Recover(); recovered || err != nil {
+		t.Fatalf("want no journal left, got %v %v", recovered, err)
+	}
+}
+
+func TestManager_UpgradeDetachFailure(t *testing.T) {
+	m, _, store, _ := newTestManager(t)
+	ctx := context.Background()
+	if err := m.Install(ctx, "1.21.4", InstallOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	store.detachErr = errors.New("interrupted")
+
+	result, err := m.Upgrade(ctx, UpgradeOptions{}, "1.21.4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.RolledBack {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	if !store.versions["1.21.4"] || store.versions["1.21.5"] || len(store.detached) != 0 {
+		t.Fatalf("unexpected versions %v, detached %v", store.versions, store.detached)
+	}
+}
+
+func TestManager_Recover(t *testing.T) {
+	m, _, store, linker := newTestManager(t)
+	ctx := context.Background()
+	if err := m.Install(ctx, "1.21.4", InstallOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Use("1.21.4"); err != nil {
+		t.Fatal(err)
+	}
+
+	// 模拟在移走旧版本并激活新版本后中断
+	j := &upgradeJournal{
+		Previous: "1.21.4",
+		Steps:    []*journalStep{{From: "1.21.4", To: "1.21.5", Installing: true, Detached: true}},
+	}
+	if err := m.saveJournal(j); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Install(ctx, "1.21.5", InstallOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	_ = store.Detach(*version.New("1.21.4"))
+	linker.target = store.GoRoot(*version.New("1.21.5"))
+
+	// 升级仍在其他进程中执行时不处理
+	j.Pid = os.Getppid()
+	if err := m.saveJournal(j); err != nil {
+		t.Fatal(err)
+	}
+	if recovered, err := m.Recover(); recovered || err != nil {
+		t.Fatalf("want running upgrade skipped, got %v %v", recovered, err)
+	}
+	if result := m.ApplyUpgrade(ctx, []UpgradeStep{{From: *version.New("1.21.5"), To: *version.New("1.22.1")}}, UpgradeOptions{}); len(result.Errors) != 1 || !errors.Is(result.Errors[0], ErrUpgradeRunning) {
+		t.Fatalf("want ErrUpgradeRunning, got %+v", result)
+	}
+
+	j.Pid = 0
+	if err := m.saveJournal(j); err != nil {
+		t.Fatal(err)
+	}
+	recovered, err := m.Recover()
+	if !recovered || err != nil {
+		t.Fatalf("want recovered, got %v %v", recovered, err)
+	}
+	if !store.versions["1.21.4"] || store.versions["1.21.5"] {
+		t.Fatalf("unexpected versions %v", store.versions)
+	}
+	current, _ := m.Current()
+	if current.String() != "1.21.4" {
+		t.Fatalf("want active 1.21.4, got %v", current)
+	}
+}
+
+func TestFindHold(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	holds := []Hold{
+		{Pattern: "1.21.*", Reason: "migration"},
+		{Pattern: "1.20.1", ExpiresAt: &past},
+	}
+
+	if h := FindHold(holds, *version.New("1.21.5")); h == nil || h.Reason != "migration" {
+		t.Errorf("want 1.21.* hold, got %v", h)
+	}
+	if h := FindHold(holds, *version.New("1.22.0")); h != nil {
+		t.Errorf("want nil, got %v", h)
+	}
+	if h := FindHold(holds, *version.New("1.20.1")); h != nil {
+		t.Errorf("want expired hold ignored, got %v", h)
+	}
+}
+
+func TestManager_Support(t *testing.T) {
+	m, _, _, _ := newTestManager(t)
+
+	tests := []struct {
+		in   string
+		want SupportStatus
+	}{
+		{"1.22.0", Supported},
+		{"1.21", Supported},
+		{"1.23rc1", Supported},
+		{"1.20.3", EOL},
+	}
+	for _, tt := range tests {
+		if got := m.Support(*version.New(tt.in)); got != tt.want {
+			t.Errorf("Support(%s) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+
+	if latest, behind := m.IsBehind(*version.New("1.21.4")); !behind || latest.String() != "1.21.5" {
+		t.Errorf("IsBehind(1.21.4) = %v, %v", latest, behind)
+	}
+	if _, behind := m.IsBehind(*version.New("1.21")); behind {
+		t.Error("minor version should not be behind")
+	}
+}
+
+func TestManager_PruneCache(t *testing.T) {
+	m, _, store, _ := newTestManager(t)
+	platform := runtime.GOOS + "-" + runtime.GOARCH
+	now := time.Now()
+	write := func(name string, size int, age time.Duration) {
+		file := filepath.Join(m.conf.CachePath, name)
+		if err := os.WriteFile(file, make([]byte, size), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(file, now.Add(-age), now.Add(-age)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("go1.20.1."+platform+".tar.gz", 10, 100*24*time.Hour)
+	write("go1.21.4."+platform+".tar.gz", 10, 50*24*time.Hour)
+	write("go1.22.0."+platform+".tar.gz", 10, 2*24*time.Hour)
+	write("go1.22.1."+platform+".tar.gz", 10, time.Hour)
+	write("go1.22.1."+platform+".tar.gz.temp", 5, 2*time.Hour)
+	store.versions["1.21.4"] = true
+
+	removed, err := m.PruneCache(PruneOptions{
+		KeepInstalled: true,
+		OlderThan:     90 * 24 * time.Hour,
+		MaxSize:       20,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, 0, len(removed))
+	for _, entry := range removed {
+		names = append(names, entry.Version.String())
+	}
+	// 1.20.1 过期，.temp 超过一小时，超出大小时跳过已安装的 1.21.4，删除最久未使用的 1.22.0
+	want := []string{"1.20.1", "1.22.1", "1.22.0"}
+	if strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Fatalf("removed %v, want %v", names, want)
+	}
+
+	size, err := m.CacheSize()
+	if err != nil || size != 20 {
+		t.Fatalf("cache size = %d, %v, want 20", size, err)
+	}
+}
+
+func writeArchive(t *testing.T, name string, files map[string]string) {
+	t.Helper()
+	f, err := os.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gw := gzip.NewWriter(f)
+	tw := tar.NewWriter(gw)
+	for file, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: file, Mode: 0o755, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestManager_VerifyRepair(t *testing.T) {
+	m, _, _, _ := newTestManager(t)
+	ctx := context.Background()
+	writeArchive(t, filepath.Join(m.conf.CachePath, "go1.21.4.tar.gz"), map[string]string{
+		"go/bin/go":     "go",
+		"go/src/fmt.go": "package fmt",
+	})
+	// fakeStore 安装时不解压，所有文件都缺失
+	if err := m.Install(ctx, "1.21.4", InstallOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	goRoot := m.GoRoot(*version.New("1.21.4"))
+	if err := os.MkdirAll(filepath.Join(goRoot, "bin"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(goRoot, "bin", "gopls"), []byte("gopls"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	diff, err := m.Verify("1.21.4", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diff.Missing) != 2 || len(diff.Added) != 1 || diff.Added[0] != "bin/gopls" {
+		t.Fatalf("Verify = %+v", diff)
+	}
+
+	if _, err := m.Repair(ctx, "1.21.4", FetchOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if diff, err := m.Verify("1.21.4", false); err != nil || !diff.Clean() {
+		t.Fatalf("Verify after repair = %+v, %v", diff, err)
+	}
+
+	if _, err := m.Verify("1.22.0", false); !errors.Is(err, ErrNotInstalled) {
+		t.Fatalf("Verify not installed version: %v", err)
+	}
+}
+
+func TestFileStore_Dedupe(t *testing.T) {
+	dir := t.TempDir()
+	store := NewFileStore(filepath.Join(dir, "install"), dir, false)
+	write := func(v, name, content string) string {
+		file := filepath.Join(store.GoRoot(*version.New(v)), name)
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return file
+	}
+	a := write("1.21.4", "src/fmt.go", "package fmt")
+	write("1.21.4", "VERSION", "go1.21.4")
+	b := write("1.21.5", "src/fmt.go", "package fmt")
+	write("1.21.5", "VERSION", "go1.21.5")
+
+	if _, err := store.Dedupe(*version.New("1.21.4")); errors.Is(err, ErrDedupeUnsupported) {
+		t.Skip(err)
+	} else if err != nil {
+		t.Fatal(err)
+	}
+	result, err := store.Dedupe(*version.New("1.21.5"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Files != 1 || result.Saved != int64(len("package fmt")) {
+		t.Fatalf("Dedupe = %+v", result)
+	}
+	infoA, _ := os.Stat(a)
+	infoB, _ := os.Stat(b)
+	if !os.SameFile(infoA, infoB) {
+		t.Fatal("identical files should be hardlinked")
+	}
+
+	// 卸载一个版本不影响另一个版本，没有版本使用的文件会被清理
+	if err := store.Remove(*version.New("1.21.4")); err != nil {
+		t.Fatal(err)
+	}
+	if buf, err := os.ReadFile(b); err != nil || string(buf) != "package fmt" {
+		t.Fatalf("read 1.21.5 file: %q, %v", buf, err)
+	}
+	objects := 0
+	_ = filepath.WalkDir(store.objectsPath(), func(_ string, d fs.DirEntry, _ error) error {
+		if d != nil && d.Type().IsRegular() {
+			objects++
+		}
+		return nil
+	})
+	if objects != 2 {
+		t.Fatalf("want 2 objects of 1.21.5, got %d", objects)
+	}
+}
+
+func TestFileStore_InstallFailureKeepsVersion(t *testing.T) {
+	dir := t.TempDir()
+	store := NewFileStore(filepath.Join(dir, "install"), dir, false)
+	v := *version.New("1.21.5")
+	archive := filepath.Join(dir, "go1.21.5.linux-amd64.tar.gz")
+	writeArchive(t, archive, map[string]string{"go/VERSION": "go1.21.5"})
+	if err := store.Install(v, archive, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// 覆盖安装失败时原来的版本仍然可用
+	broken := filepath.Join(dir, "broken.tar.gz")
+	if err := os.WriteFile(broken, []byte("not a tarball"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Install(v, broken, nil); err == nil {
+		t.Fatal("want error for broken archive")
+	}
+	if buf, err := os.ReadFile(filepath.Join(store.GoRoot(v), "VERSION")); err != nil || string(buf) != "go1.21.5" {
+		t.Fatalf("read VERSION: %q, %v", buf, err)
+	}
+	if versions, _ := store.Versions(); len(versions) != 1 {
+		t.Fatalf("unexpected versions %v", versions)
+	}
+
+	if err := store.Install(v, archive, nil); err != nil {
+		t.Fatal(err)
+	}
+	if buf, err := os.ReadFile(filepath.Join(store.GoRoot(v), "VERSION")); err != nil || string(buf) != "go1.21.5" {
+		t.Fatalf("read VERSION after reinstall: %q, %v", buf, err)
+	}
+}
+
+func TestSlimProfile_Excluded(t *testing.T) {
+	p := &DefaultSlimProfile
+	tests := []struct {
+		in   string
+		want bool
+	}{
+		{"test/run.go", true},
+		{"doc/", true},
+		{"api/go1.txt", true},
+		{"src/fmt/testdata/a.txt", true},
+		{"src/cmd/go/testdata", true},
+		{"src/fmt/print.go", false},
+		{"src/testing/testing.go", false},
+		{"bin/go", false},
+		{"src/test/x.go", false},
+	}
+	for _, tt := range tests {
+		if got := p.Excluded(tt.in); got != tt.want {
+			t.Errorf("Excluded(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+	if (*SlimProfile)(nil).Excluded("test") {
+		t.Error("nil profile should not exclude anything")
+	}
+}
@@ -0,0 +1,215 @@
+package manager
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/serious-snow/govm/pkg/utils/path"
+	"github.com/serious-snow/govm/pkg/version"
+)
+
+// ErrNoManifest 安装时没有记录文件清单，如旧版本 govm 安装的版本
+var ErrNoManifest = errors.New("没有文件清单")
+
+// archiveRoot 归档文件中 GOROOT 所在的目录
+const archiveRoot = "go/"
+
+// ManifestFile 文件清单中的文件，Path 为相对 GOROOT 的路径，使用 / 分隔
+type ManifestFile struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	Sha256 string `json:"sha256"`
+}
+
+// Manifest 安装时根据归档文件记录的文件清单
+type Manifest struct {
+	Version   string         `json:"version"`
+	Archive   string         `json:"archive"`
+	CreatedAt time.Time      `json:"createdAt"`
+	Files     []ManifestFile `json:"files"`
+	// Slim 精简安装时排除的文件不在清单中，校验和修复时不会视为缺失
+	Slim *SlimProfile `json:"slim,omitempty"`
+}
+
+// TreeDiff GOROOT 与文件清单的差异，路径相对 GOROOT
+type TreeDiff struct {
+	Modified []string // 内容被修改
+	Missing  []string // 被删除
+	Added    []string // 清单中没有的文件，如 go install 到 GOROOT 的文件
+}
+
+// Clean 没有差异
+func (d *TreeDiff) Clean() bool {
+	return len(d.Modified) == 0 && len(d.Missing) == 0 && len(d.Added) == 0
+}
+
+// BuildManifest 根据归档文件生成文件清单，slim 不为空时不包含其排除的文件
+func BuildManifest(v version.Version, archive string, slim *SlimProfile) (*Manifest, error) {
+	manifest := &Manifest{
+		Version:   v.String(),
+		Archive:   filepath.Base(archive),
+		CreatedAt: time.Now(),
+		Slim:      slim,
+	}
+	err := path.WalkArchive(archive, func(name string, r io.Reader) error {
+		if !strings.HasPrefix(name, archiveRoot) || slim.excludedInArchive(name) {
+			return nil
+		}
+		sha := sha256.New()
+		size, err := io.Copy(sha, r)
+		if err != nil {
+			return err
+		}
+		manifest.Files = append(manifest.Files, ManifestFile{
+			Path:   strings.TrimPrefix(name, archiveRoot),
+			Size:   size,
+			Sha256: hex.EncodeToString(sha.Sum(nil)),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// DiffTree 比较 root 与文件清单，quick 为 true 时只比较文件大小，不计算 sha256
+func DiffTree(root string, manifest *Manifest, quick bool) (*TreeDiff, error) {
+	diff := &TreeDiff{}
+	known := make(map[string]bool, len(manifest.Files))
+	for _, file := range manifest.Files {
+		known[file.Path] = true
+		name := filepath.Join(root, filepath.FromSlash(file.Path))
+		info, err := os.Stat(name)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				return nil, err
+			}
+			diff.Missing = append(diff.Missing, file.Path)
+			continue
+		}
+		if info.Size() != file.Size || (!quick && fileSha256(name) != file.Sha256) {
+			diff.Modified = append(diff.Modified, file.Path)
+		}
+	}
+
+	err := filepath.WalkDir(root, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, name)
+		if err != nil {
+			return err
+		}
+		if rel = filepath.ToSlash(rel); !known[rel] {
+			diff.Added = append(diff.Added, rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(diff.Added)
+	return diff, nil
+}
+
+func fileSha256(name string) string {
+	f, err := os.Open(name)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	sha := sha256.New()
+	if _, err := io.Copy(sha, f); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(sha.Sum(nil))
+}
+
+// Verify 比较已安装版本的 GOROOT 与安装时记录的文件清单
+func (m *Manager) Verify(ver string, quick bool) (*TreeDiff, error) {
+	v := version.New(TrimVersion(ver))
+	if !m.IsInstalled(*v) {
+		return nil, versionError(v, ErrNotInstalled)
+	}
+	manifest, err := m.conf.Store.Manifest(*v)
+	if err != nil {
+		return nil, err
+	}
+	if manifest == nil {
+		return nil, versionError(v, ErrNoManifest)
+	}
+	return DiffTree(m.GoRoot(*v), manifest, quick)
+}
+
+// Repair 使用缓存的归档文件恢复被修改或删除的文件，删除清单中没有的文件，
+// 没有缓存时重新下载，没有文件清单时根据归档文件和安装时的精简配置生成，返回修复的差异
+func (m *Manager) Repair(ctx context.Context, ver string, opts FetchOptions) (*TreeDiff, error) {
+	v := version.New(TrimVersion(ver))
+	if !m.IsInstalled(*v) {
+		return nil, versionError(v, ErrNotInstalled)
+	}
+	info := m.RemoteInfo(*v)
+	if info == nil {
+		return nil, versionError(v, ErrNotFound)
+	}
+
+	unlock := m.lock(v.String())
+	defer unlock()
+
+	archive, err := m.Fetch(ctx, info, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest, err := m.conf.Store.Manifest(*v)
+	if err != nil {
+		return nil, err
+	}
+	if manifest == nil {
+		var slim *SlimProfile
+		if meta := m.Meta(*v); meta != nil {
+			slim = meta.Slim
+		}
+		if manifest, err = BuildManifest(*v, archive, slim); err != nil {
+			return nil, err
+		}
+		if err := m.conf.Store.SaveManifest(*v, manifest); err != nil {
+			return nil, err
+		}
+	}
+
+	goRoot := m.GoRoot(*v)
+	diff, err := DiffTree(goRoot, manifest, false)
+	if err != nil || diff.Clean() {
+		return diff, err
+	}
+
+	for _, name := range diff.Added {
+		if err := os.Remove(filepath.Join(goRoot, filepath.FromSlash(name))); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+	names := make(map[string]bool, len(diff.Modified)+len(diff.Missing))
+	for _, name := range append(diff.Modified, diff.Missing...) {
+		names[archiveRoot+name] = true
+	}
+	if len(names) != 0 {
+		if err := path.DecompressFiles(archive, filepath.Dir(goRoot), names); err != nil {
+			return nil, err
+		}
+	}
+	return diff, nil
+}
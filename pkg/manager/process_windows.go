@@ -0,0 +1,15 @@
+package manager
+
+import (
+	"os"
+)
+
+// processAlive 进程是否仍在运行，Windows 下进程不存在时无法打开
+func processAlive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	_ = p.Release()
+	return true
+}
@@ -0,0 +1,51 @@
+package manager
+
+import (
+	"path"
+	"strings"
+)
+
+// SlimProfile 精简安装时排除的文件
+type SlimProfile struct {
+	Name string `json:"name"`
+	// Exclude 相对 GOROOT 的路径模式，匹配文件或文件夹，支持 path.Match 的通配符，
+	// 以 **/ 开头时匹配任意层级，如 **/testdata
+	Exclude []string `json:"exclude"`
+}
+
+// DefaultSlimProfile 默认的精简安装配置，只保留编译器、工具和标准库
+var DefaultSlimProfile = SlimProfile{
+	Name:    "default",
+	Exclude: []string{"api", "doc", "misc", "test", "**/testdata"},
+}
+
+// Excluded 相对 GOROOT 的路径是否被排除，文件夹被排除时其中的文件也被排除
+func (p *SlimProfile) Excluded(rel string) bool {
+	if p == nil {
+		return false
+	}
+	parts := strings.Split(strings.Trim(rel, "/"), "/")
+	for _, pattern := range p.Exclude {
+		pattern = strings.Trim(pattern, "/")
+		anyDepth := strings.HasPrefix(pattern, "**/")
+		pattern = strings.TrimPrefix(pattern, "**/")
+		n := strings.Count(pattern, "/") + 1
+		for i := 0; i+n <= len(parts); i++ {
+			if ok, _ := path.Match(pattern, strings.Join(parts[i:i+n], "/")); ok {
+				return true
+			}
+			if !anyDepth {
+				break
+			}
+		}
+	}
+	return false
+}
+
+// excludedInArchive 归档文件中的路径是否被排除，GOROOT 以外的路径不排除
+func (p *SlimProfile) excludedInArchive(name string) bool {
+	if !strings.HasPrefix(name, archiveRoot) {
+		return false
+	}
+	return p.Excluded(strings.TrimPrefix(name, archiveRoot))
+}
@@ -0,0 +1,243 @@
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"net/url"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/serious-snow/govm/pkg/utils/httpc"
+	"github.com/serious-snow/govm/pkg/version"
+	"github.com/serious-snow/govm/types"
+)
+
+// bucketLink go 安装包所在的存储桶，用于获取发布日期
+const bucketLink = "https://storage.googleapis.com/golang/"
+
+// Source 远程版本源
+type Source interface {
+	// List 获取远程版本列表，按版本从新到旧排序
+	List(ctx context.Context) ([]*GoVersionInfo, error)
+	// Download 下载版本归档文件到 dir 目录，sha256 为空时不校验
+	Download(ctx context.Context, info *GoVersionInfo, dir, sha256 string) error
+	// URL 归档文件的下载地址
+	URL(info *GoVersionInfo) string
+}
+
+// PlatformSource 支持获取其他平台版本列表的版本源，用于只下载不安装
+type PlatformSource interface {
+	ListPlatform(ctx context.Context, goos, goarch string) ([]*GoVersionInfo, error)
+}
+
+// ReleaseDateSource 支持查询发布日期的版本源，只查询本地版本列表中还没有发布日期的文件
+type ReleaseDateSource interface {
+	// ReleaseDates 查询归档文件的发布日期，key 为文件名，查不到的文件不在结果中
+	ReleaseDates(ctx context.Context, filenames []string) (map[string]time.Time, error)
+}
+
+type listGoVersionResponse struct {
+	Version string `json:"version"`
+	Stable  bool   `json:"stable"`
+	Files   []*struct {
+		Filename string `json:"filename"`
+		Os       string `json:"os"`
+		Arch     string `json:"arch"`
+		Version  string `json:"version"`
+		Sha256   string `json:"sha256"`
+		Size     int    `json:"size"`
+		Kind     string `json:"kind"`
+	} `json:"files"`
+}
+
+// DLSource go.dev/dl 版本源
+type DLSource struct {
+	link string
+}
+
+// NewDLSource link 如：https://go.dev/dl/
+func NewDLSource(link string) *DLSource {
+	return &DLSource{link: link}
+}
+
+func (s *DLSource) List(ctx context.Context) ([]*GoVersionInfo, error) {
+	return s.ListPlatform(ctx, runtime.GOOS, runtime.GOARCH)
+}
+
+// ListPlatform 指定平台的版本列表
+func (s *DLSource) ListPlatform(ctx context.Context, goos, goarch string) ([]*GoVersionInfo, error) {
+	// https://go.dev/dl/?mode=json&include=all
+	link := s.link + "?mode=json&include=all"
+
+	var result []*listGoVersionResponse
+	buf, err := httpc.GetContext(ctx, link)
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(buf, &result); err != nil {
+		return nil, err
+	}
+	list := make([]*GoVersionInfo, 0, len(result))
+
+	seen := map[string]struct{}{}
+	for _, response := range result {
+		kinds, platforms := responseFileSummary(response)
+		for _, file := range response.Files {
+
+			vv := TrimVersion(file.Version)
+			if file.Kind != "archive" {
+				continue
+			}
+			if file.Os != goos || file.Arch != goarch {
+				continue
+			}
+			if _, ok := seen[vv]; ok {
+				continue
+			}
+			seen[vv] = struct{}{}
+
+			v := version.New(vv)
+			if v == nil {
+				continue
+			}
+			list = append(list, &GoVersionInfo{
+				Filename:  file.Filename,
+				Sha256:    file.Sha256,
+				Size:      file.Size,
+				Version:   *v,
+				Stable:    response.Stable,
+				Kinds:     kinds,
+				Platforms: platforms,
+			})
+		}
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Version.Greater(list[j].Version)
+	})
+
+	return list, nil
+}
+
+// responseFileSummary 版本提供的文件类型，如 archive、installer、source，以及支持的平台，如 linux/amd64
+func responseFileSummary(response *listGoVersionResponse) (kinds, platforms []string) {
+	seenKind, seenPlatform := map[string]struct{}{}, map[string]struct{}{}
+	for _, file := range response.Files {
+		if _, ok := seenKind[file.Kind]; !ok && file.Kind != "" {
+			seenKind[file.Kind] = struct{}{}
+			kinds = append(kinds, file.Kind)
+		}
+		if file.Os == "" || file.Arch == "" {
+			continue
+		}
+		platform := file.Os + "/" + file.Arch
+		if _, ok := seenPlatform[platform]; !ok {
+			seenPlatform[platform] = struct{}{}
+			platforms = append(platforms, platform)
+		}
+	}
+	sort.Strings(kinds)
+	sort.Strings(platforms)
+	return kinds, platforms
+}
+
+// ReleaseDates go.dev 的 json 中没有发布日期，使用存储桶中文件的最后修改时间，
+// 按次版本号查询，如 go1.22 前缀，避免遍历整个存储桶
+func (s *DLSource) ReleaseDates(ctx context.Context, filenames []string) (map[string]time.Time, error) {
+	wanted := make(map[string]bool, len(filenames))
+	prefixes := make([]string, 0)
+	seen := map[string]bool{}
+	for _, name := range filenames {
+		wanted[name] = true
+		match := archiveReg.FindStringSubmatch(name)
+		if match == nil {
+			continue
+		}
+		v := version.New(match[1])
+		if v == nil {
+			continue
+		}
+		prefix := "go" + v.MinorVersion()
+		if !seen[prefix] {
+			seen[prefix] = true
+			prefixes = append(prefixes, prefix)
+		}
+	}
+
+	dates := make(map[string]time.Time, len(filenames))
+	for _, prefix := range prefixes {
+		if err := listBucket(ctx, prefix, func(key string, t time.Time) {
+			if wanted[key] {
+				dates[key] = t
+			}
+		}); err != nil {
+			return dates, err
+		}
+	}
+	return dates, nil
+}
+
+// listBucket 遍历存储桶中以 prefix 开头的文件
+func listBucket(ctx context.Context, prefix string, fn func(key string, lastModified time.Time)) error {
+	var result types.ListBucketResult
+	marker := ""
+	for {
+		query := url.Values{"prefix": {prefix}}
+		if marker != "" {
+			query.Set("marker", marker)
+		}
+		buf, err := httpc.GetContext(ctx, bucketLink+"?"+query.Encode())
+		if err != nil {
+			return err
+		}
+		result.Reset()
+		if err = xml.Unmarshal(buf, &result); err != nil {
+			return err
+		}
+		for _, content := range result.Contents {
+			if t, err := time.Parse(time.RFC3339, content.LastModified); err == nil {
+				fn(content.Key, t)
+			}
+		}
+		if result.IsTruncated != "true" || len(result.Contents) == 0 {
+			return nil
+		}
+		marker = result.NextMarker
+		if marker == "" {
+			marker = result.Contents[len(result.Contents)-1].Key
+		}
+	}
+}
+
+func (s *DLSource) Download(ctx context.Context, info *GoVersionInfo, dir, sha256 string) error {
+	return httpc.DownloadWithProgress(ctx, s.URL(info), dir, info.Filename, sha256, progressFrom(ctx))
+}
+
+func (s *DLSource) URL(info *GoVersionInfo) string {
+	return s.link + info.Filename
+}
+
+type progressKey struct{}
+
+// WithProgress 下载时使用 progress 返回的进度条，用于同时下载多个版本
+func WithProgress(ctx context.Context, progress httpc.ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, progress)
+}
+
+func progressFrom(ctx context.Context) httpc.ProgressFunc {
+	if progress, ok := ctx.Value(progressKey{}).(httpc.ProgressFunc); ok && progress != nil {
+		return progress
+	}
+	return httpc.StartBar
+}
+
+// TrimVersion 去除版本号的 go、v 前缀
+func TrimVersion(version string) string {
+	version = strings.TrimSpace(version)
+	version = strings.TrimPrefix(version, "go")
+	version = strings.TrimPrefix(version, "v")
+	return version
+}
@@ -0,0 +1,262 @@
+package manager
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/serious-snow/govm/pkg/utils/path"
+	"github.com/serious-snow/govm/pkg/version"
+)
+
+// Store 本地版本存储
+type Store interface {
+	// Versions 已安装的版本列表
+	Versions() ([]*version.Version, error)
+	// Install 将归档文件解压为指定版本，slim 不为空时跳过其排除的文件
+	Install(v version.Version, archive string, slim *SlimProfile) error
+	// Remove 删除指定版本
+	Remove(v version.Version) error
+	// Detach 将指定版本移走，移走后不再出现在已安装列表中
+	Detach(v version.Version) error
+	// Restore 恢复被移走的版本，没有被移走时不做任何事
+	Restore(v version.Version) error
+	// Purge 删除被移走的版本
+	Purge(v version.Version) error
+	// GoRoot 指定版本的 GOROOT
+	GoRoot(v version.Version) string
+	// Holds 保留记录列表
+	Holds() ([]Hold, error)
+	// SaveHolds 保存保留记录列表
+	SaveHolds(holds []Hold) error
+	// Manifest 安装时记录的文件清单，没有记录时返回 nil
+	Manifest(v version.Version) (*Manifest, error)
+	// SaveManifest 保存文件清单
+	SaveManifest(v version.Version, manifest *Manifest) error
+	// Meta 安装时记录的版本来源信息，没有记录时返回 nil
+	Meta(v version.Version) (*InstallMeta, error)
+	// SaveMeta 保存版本来源信息
+	SaveMeta(v version.Version, meta *InstallMeta) error
+}
+
+// FileStore 基于文件系统的存储，版本安装于 installPath/<version>/go
+type FileStore struct {
+	installPath string
+	cachePath   string
+	// dedupe 安装时将与其他版本相同的文件替换为硬链接
+	dedupe bool
+}
+
+func NewFileStore(installPath, cachePath string, dedupe bool) *FileStore {
+	return &FileStore{installPath: installPath, cachePath: cachePath, dedupe: dedupe}
+}
+
+func (s *FileStore) Versions() ([]*version.Version, error) {
+	fileInfoList, err := os.ReadDir(s.installPath)
+	if err != nil {
+		return nil, err
+	}
+	versions := make([]*version.Version, 0)
+	for _, info := range fileInfoList {
+		if !info.IsDir() {
+			continue
+		}
+
+		vInfo := version.New(info.Name())
+		if vInfo.Valid() {
+			versions = append(versions, vInfo)
+		}
+	}
+
+	version.SortV(versions).Reverse()
+	return versions, nil
+}
+
+// Install 先解压到 installPath/.tmp/<version>，成功后再替换已安装的版本，
+// 覆盖安装失败时（如归档文件损坏、磁盘已满、被中断）不会破坏原来可用的版本
+func (s *FileStore) Install(v version.Version, archive string, slim *SlimProfile) error {
+	toPath := filepath.Join(s.installPath, v.String())
+	temp := filepath.Join(s.installPath, ".tmp", v.String())
+	if err := os.RemoveAll(temp); err != nil {
+		return err
+	}
+	if err := path.MakeDir(filepath.Dir(temp)); err != nil {
+		return err
+	}
+	var skip func(name string) bool
+	if slim != nil {
+		skip = slim.excludedInArchive
+	}
+	if err := path.DecompressFilter(archive, temp, skip); err != nil {
+		_ = os.RemoveAll(temp)
+		return err
+	}
+
+	// 已有文件可能是与其他版本共享的硬链接，整个目录替换而不是逐个覆盖，避免修改其他版本的文件
+	old := temp + ".old"
+	replaced := path.PathIsExisted(toPath)
+	if replaced {
+		if err := os.RemoveAll(old); err != nil {
+			return err
+		}
+		if err := os.Rename(toPath, old); err != nil {
+			_ = os.RemoveAll(temp)
+			return err
+		}
+	}
+	if err := os.Rename(temp, toPath); err != nil {
+		if replaced {
+			_ = os.Rename(old, toPath)
+		}
+		_ = os.RemoveAll(temp)
+		return err
+	}
+	if replaced {
+		_ = os.RemoveAll(old)
+		_ = s.pruneObjects()
+	}
+
+	if s.dedupe {
+		// 去重失败时保留完整的文件，不影响安装
+		_, _ = s.Dedupe(v)
+	}
+	return nil
+}
+
+// Remove 删除版本目录，硬链接只减少链接数，不会影响其他版本的文件
+func (s *FileStore) Remove(v version.Version) error {
+	if err := os.RemoveAll(filepath.Join(s.installPath, v.String())); err != nil {
+		return err
+	}
+	return s.pruneObjects()
+}
+
+// trashPath 被移走的版本存放于 installPath/.trash/<version>
+func (s *FileStore) trashPath(v version.Version) string {
+	return filepath.Join(s.installPath, ".trash", v.String())
+}
+
+func (s *FileStore) Detach(v version.Version) error {
+	trash := s.trashPath(v)
+	if err := os.RemoveAll(trash); err != nil {
+		return err
+	}
+	if err := path.MakeDir(filepath.Dir(trash)); err != nil {
+		return err
+	}
+	return os.Rename(filepath.Join(s.installPath, v.String()), trash)
+}
+
+func (s *FileStore) Restore(v version.Version) error {
+	trash := s.trashPath(v)
+	if !path.PathIsExisted(trash) {
+		return nil
+	}
+	toPath := filepath.Join(s.installPath, v.String())
+	if err := os.RemoveAll(toPath); err != nil {
+		return err
+	}
+	return os.Rename(trash, toPath)
+}
+
+func (s *FileStore) Purge(v version.Version) error {
+	if err := os.RemoveAll(s.trashPath(v)); err != nil {
+		return err
+	}
+	return s.pruneObjects()
+}
+
+func (s *FileStore) GoRoot(v version.Version) string {
+	return filepath.Join(s.installPath, v.String(), "go")
+}
+
+func (s *FileStore) Holds() ([]Hold, error) {
+	buf, err := os.ReadFile(filepath.Join(s.cachePath, "hold.json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	holds := make([]Hold, 0)
+	if err := json.Unmarshal(buf, &holds); err == nil {
+		return holds, nil
+	}
+
+	// 兼容旧版本的 []string 格式
+	old := make([]string, 0)
+	if err := json.Unmarshal(buf, &old); err != nil {
+		return nil, err
+	}
+	holds = make([]Hold, 0, len(old))
+	for _, v := range old {
+		holds = append(holds, Hold{Pattern: v})
+	}
+	return holds, nil
+}
+
+func (s *FileStore) SaveHolds(holds []Hold) error {
+	filename := filepath.Join(s.cachePath, "hold.json")
+	file, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return json.NewEncoder(file).Encode(holds)
+}
+
+// manifestPath 文件清单存放于 installPath/<version>/manifest.json，不在 GOROOT 内
+func (s *FileStore) manifestPath(v version.Version) string {
+	return filepath.Join(s.installPath, v.String(), "manifest.json")
+}
+
+func (s *FileStore) Manifest(v version.Version) (*Manifest, error) {
+	buf, err := os.ReadFile(s.manifestPath(v))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	manifest := &Manifest{}
+	if err := json.Unmarshal(buf, manifest); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+func (s *FileStore) SaveManifest(v version.Version, manifest *Manifest) error {
+	buf, err := json.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.manifestPath(v), buf, 0o644)
+}
+
+// metaPath 版本来源信息存放于 installPath/<version>/meta.json
+func (s *FileStore) metaPath(v version.Version) string {
+	return filepath.Join(s.installPath, v.String(), "meta.json")
+}
+
+func (s *FileStore) Meta(v version.Version) (*InstallMeta, error) {
+	buf, err := os.ReadFile(s.metaPath(v))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	meta := &InstallMeta{}
+	if err := json.Unmarshal(buf, meta); err != nil {
+		return nil, err
+	}
+	return meta, nil
+}
+
+func (s *FileStore) SaveMeta(v version.Version, meta *InstallMeta) error {
+	buf, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.metaPath(v), buf, 0o644)
+}
@@ -0,0 +1,78 @@
+package manager
+
+import (
+	"github.com/serious-snow/govm/pkg/version"
+)
+
+// supportedMinorCount go 官方只维护最新的两个次版本
+const supportedMinorCount = 2
+
+// SupportStatus 版本的维护状态
+type SupportStatus int
+
+const (
+	SupportUnknown SupportStatus = iota // 远程列表为空，无法判断
+	Supported                           // 仍在维护
+	EOL                                 // 已停止维护
+)
+
+func (s SupportStatus) String() string {
+	switch s {
+	case Supported:
+		return "supported"
+	case EOL:
+		return "EOL"
+	default:
+		return "unknown"
+	}
+}
+
+// SupportedMinors 仍在维护的次版本，如 1.22、1.21，根据远程列表中最新的稳定版本计算
+func (m *Manager) SupportedMinors() []string {
+	minors := make([]string, 0, supportedMinorCount)
+	for _, info := range m.remote {
+		if !info.Stable {
+			continue
+		}
+		minor := info.Version.MinorVersion()
+		if len(minors) > 0 && minors[len(minors)-1] == minor {
+			continue
+		}
+		minors = append(minors, minor)
+		if len(minors) == supportedMinorCount {
+			break
+		}
+	}
+	return minors
+}
+
+// Support 版本的维护状态，比最新稳定版本更新的预发布版本视为仍在维护
+func (m *Manager) Support(v version.Version) SupportStatus {
+	minors := m.SupportedMinors()
+	if len(minors) == 0 {
+		return SupportUnknown
+	}
+	newest := version.New(minors[0])
+	if v.Major > newest.Major || (v.Major == newest.Major && v.Minor > newest.Minor) {
+		return Supported
+	}
+	for _, minor := range minors {
+		if v.MinorVersion() == minor {
+			return Supported
+		}
+	}
+	return EOL
+}
+
+// IsBehind 是否落后于同一次版本的最新补丁版本，返回最新补丁版本，
+// 只有次版本号（如 1.21）时不视为落后
+func (m *Manager) IsBehind(v version.Version) (*version.Version, bool) {
+	if v.Patch == nil {
+		return nil, false
+	}
+	latest := m.PatchNewest(v)
+	if latest == nil || !latest.Greater(v) {
+		return nil, false
+	}
+	return latest, true
+}
@@ -0,0 +1,92 @@
+package manager
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/serious-snow/govm/pkg/version"
+)
+
+var (
+	ErrNotFound         = errors.New("暂未找到该版本资源下载")
+	ErrNotInstalled     = errors.New("未安装")
+	ErrAlreadyInstalled = errors.New("已经安装")
+	ErrHeld             = errors.New("被标记为保留")
+	ErrBrokenInstall    = errors.New("go 文件夹不存在，请重新安装")
+	ErrNoActive         = errors.New("当前没有激活的版本")
+	ErrUpgradeRunning   = errors.New("另一个 govm 进程正在升级")
+)
+
+// 版本别名
+const (
+	AliasLatest = "latest" // 最新版本，包括 rc、beta
+	AliasStable = "stable" // 最新稳定版本
+)
+
+// IsAlias 是否为版本别名
+func IsAlias(s string) bool {
+	return s == AliasLatest || s == AliasStable
+}
+
+// VersionError 与具体版本相关的错误
+type VersionError struct {
+	Version string
+	Err     error
+}
+
+func (e *VersionError) Error() string {
+	return e.Version + " " + e.Err.Error()
+}
+
+func (e *VersionError) Unwrap() error {
+	return e.Err
+}
+
+func versionError(v any, err error) error {
+	return &VersionError{Version: fmt.Sprint(v), Err: err}
+}
+
+// GoVersionInfo 远程版本信息
+type GoVersionInfo struct {
+	Filename string          `json:"filename"`
+	Version  version.Version `json:"version"`
+	Sha256   string          `json:"sha256"`
+	Size     int             `json:"size"`
+	// ReleasedAt 发布日期，来自存储桶中文件的最后修改时间，可能为空
+	ReleasedAt *time.Time `json:"releasedAt,omitempty"`
+	// Stable go.dev 标记的稳定版本
+	Stable bool `json:"stable"`
+	// Kinds 该版本提供的文件类型，如 archive、installer、source
+	Kinds []string `json:"kinds,omitempty"`
+	// Platforms 该版本支持的平台，如 linux/amd64
+	Platforms []string `json:"platforms,omitempty"`
+}
+
+// InstallOptions 安装选项
+type InstallOptions struct {
+	Force        bool // 覆盖已安装的版本
+	IgnoreSha256 bool // 不校验 sha256
+	Quiet        bool // 不输出下载提示，如同时安装多个版本时由进度条显示
+	// Slim 精简安装时排除的文件，为空时完整安装
+	Slim *SlimProfile
+}
+
+// InstallMeta 安装时记录的版本来源信息
+type InstallMeta struct {
+	Version      string    `json:"version"`
+	Filename     string    `json:"filename"`
+	URL          string    `json:"url"`
+	Sha256       string    `json:"sha256"` // 远程列表中的 sha256，可能为空
+	IgnoreSha256 bool      `json:"ignoreSha256"`
+	InstalledAt  time.Time `json:"installedAt"`
+	GovmVersion  string    `json:"govmVersion"`
+	// Slim 精简安装的配置，完整安装时为空
+	Slim *SlimProfile `json:"slim,omitempty"`
+}
+
+// FetchOptions 只下载不安装的选项
+type FetchOptions struct {
+	IgnoreSha256 bool // 不校验 sha256
+	Quiet        bool // 不输出下载提示
+}
@@ -0,0 +1,250 @@
+package manager
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/serious-snow/govm/pkg/version"
+)
+
+// UpgradeOptions 升级选项
+type UpgradeOptions struct {
+	KeepOld bool // 安装新版本但不卸载旧版本
+	Minor   bool // 升级到最新的稳定次版本，而不只是同一次版本下的最新补丁版本
+}
+
+// UpgradeStep 升级步骤，将 From 升级到 To
+type UpgradeStep struct {
+	From version.Version
+	To   version.Version
+	Hold *Hold // 不为空时表示 From 被保留，该步骤会被跳过
+}
+
+// UpgradeResult 升级结果
+type UpgradeResult struct {
+	Total       int
+	Installed   int
+	Uninstalled int
+	Ignored     int
+	RolledBack  bool // 升级失败并已回滚
+	Errors      []error
+}
+
+// PatchNewest 同一次版本下的最新补丁版本
+func (m *Manager) PatchNewest(v version.Version) *version.Version {
+	for _, info := range m.remote {
+		if info.Version.Major == v.Major && info.Version.Minor == v.Minor {
+			return &info.Version
+		}
+	}
+	return nil
+}
+
+// NewestStable 最新的稳定版本
+func (m *Manager) NewestStable() *version.Version {
+	for _, info := range m.remote {
+		if info.Stable {
+			return &info.Version
+		}
+	}
+	return nil
+}
+
+// Upgradeable 可升级的版本，key 为最新补丁版本，value 为已安装的旧版本
+func (m *Manager) Upgradeable() map[string][]*version.Version {
+	result := make(map[string][]*version.Version)
+
+	for _, v := range m.List(true) {
+		latest := m.PatchNewest(*v)
+		if latest == nil {
+			continue
+		}
+
+		if latest.Equal(*v) {
+			continue
+		}
+
+		result[latest.String()] = append(result[latest.String()], v)
+	}
+	return result
+}
+
+// upgradeTarget 版本的升级目标，没有可升级的版本时返回 nil
+func (m *Manager) upgradeTarget(v version.Version, opts UpgradeOptions) *version.Version {
+	if opts.Minor {
+		if newest := m.NewestStable(); newest != nil && newest.Greater(v) {
+			return newest
+		}
+	}
+	if newest := m.PatchNewest(v); newest != nil && newest.Greater(v) {
+		return newest
+	}
+	return nil
+}
+
+// PlanUpgrade 生成升级计划，未指定版本时包含所有可升级的版本，
+// 被保留的版本也会出现在计划中，但会被标记并在执行时跳过
+func (m *Manager) PlanUpgrade(opts UpgradeOptions, versions ...string) ([]UpgradeStep, error) {
+	steps := make([]UpgradeStep, 0)
+
+	if len(versions) == 0 {
+		installed := m.List(true)
+		// 未指定版本时只有最新的次版本升级到新的次版本，其他次版本只升级补丁版本，
+		// 避免所有次版本都升级到同一个版本后被卸载
+		newestMinor := ""
+		if len(installed) != 0 {
+			newest := installed[0]
+			for _, v := range installed[1:] {
+				if v.Greater(*newest) {
+					newest = v
+				}
+			}
+			newestMinor = newest.MinorVersion()
+		}
+		for _, v := range installed {
+			vOpts := opts
+			vOpts.Minor = opts.Minor && v.MinorVersion() == newestMinor
+			to := m.upgradeTarget(*v, vOpts)
+			if to == nil {
+				continue
+			}
+			steps = append(steps, UpgradeStep{From: *v, To: *to, Hold: m.HoldOf(*v)})
+		}
+		return steps, nil
+	}
+
+	for _, ver := range versions {
+		v := version.New(TrimVersion(ver))
+		if !m.IsInstalled(*v) {
+			return nil, versionError(v, ErrNotInstalled)
+		}
+		if m.IsHeld(v.String()) {
+			return nil, versionError(v, ErrHeld)
+		}
+		if m.PatchNewest(*v) == nil {
+			return nil, versionError(v, ErrNotFound)
+		}
+		to := m.upgradeTarget(*v, opts)
+		if to == nil {
+			continue
+		}
+		steps = append(steps, UpgradeStep{From: *v, To: *to})
+	}
+	return steps, nil
+}
+
+// ApplyUpgrade 执行升级计划，同一目标版本只安装一次，
+// 卸载的旧版本为当前激活版本时，激活目标版本。
+// 升级过程记录在日志中，旧版本先被移走，全部成功后才删除，
+// 任一步骤失败时回滚，恢复旧版本和升级前激活的版本
+func (m *Manager) ApplyUpgrade(ctx context.Context, steps []UpgradeStep, opts UpgradeOptions) *UpgradeResult {
+	result := &UpgradeResult{Total: len(steps)}
+	current, _ := m.Current()
+
+	// 日志属于仍在执行的升级时不能覆盖
+	if running, _ := m.loadJournal(); running != nil && running.running() {
+		result.Errors = append(result.Errors, ErrUpgradeRunning)
+		return result
+	}
+
+	j := &upgradeJournal{KeepOld: opts.KeepOld, CreatedAt: time.Now(), Pid: os.Getpid()}
+	if current.Valid() {
+		j.Previous = current.String()
+	}
+	for _, step := range steps {
+		if step.Hold != nil {
+			result.Ignored++
+			continue
+		}
+		j.Steps = append(j.Steps, &journalStep{
+			From:         step.From.String(),
+			To:           step.To.String(),
+			PreInstalled: m.IsInstalled(step.To),
+		})
+	}
+	if len(j.Steps) == 0 {
+		return result
+	}
+	if err := m.saveJournal(j); err != nil {
+		result.Errors = append(result.Errors, err)
+		return result
+	}
+
+	for _, s := range j.Steps {
+		installed, err := m.applyStep(ctx, j, s, current)
+		if err != nil {
+			result.Errors = append(result.Errors, err)
+			if err := m.rollback(j); err != nil {
+				result.Errors = append(result.Errors, err)
+			}
+			result.RolledBack = true
+			result.Installed = 0
+			return result
+		}
+		if installed {
+			result.Installed++
+		}
+	}
+
+	removed, err := m.commit(j)
+	for _, v := range removed {
+		m.conf.Logf("%s 卸载成功\n", v)
+	}
+	result.Uninstalled = len(removed)
+	if err != nil {
+		result.Errors = append(result.Errors, err)
+	}
+	return result
+}
+
+// applyStep 安装目标版本并移走旧版本，返回是否新安装了目标版本
+func (m *Manager) applyStep(ctx context.Context, j *upgradeJournal, s *journalStep, current version.Version) (bool, error) {
+	installed := false
+	if !m.IsInstalled(*version.New(s.To)) {
+		s.Installing = true
+		if err := m.saveJournal(j); err != nil {
+			return false, err
+		}
+		// 精简安装的版本升级后仍使用相同的精简配置
+		opts := InstallOptions{IgnoreSha256: true}
+		if meta := m.Meta(*version.New(s.From)); meta != nil {
+			opts.Slim = meta.Slim
+		}
+		if err := m.Install(ctx, s.To, opts); err != nil {
+			return false, err
+		}
+		installed = true
+	}
+
+	if j.KeepOld {
+		return installed, nil
+	}
+
+	// 先记录再移走，移走后中断时回滚也能恢复旧版本，Restore 在旧版本未被移走时不做处理
+	from := *version.New(s.From)
+	s.Detached = true
+	if err := m.saveJournal(j); err != nil {
+		return installed, err
+	}
+	if err := m.conf.Store.Detach(from); err != nil {
+		return installed, err
+	}
+
+	// 如果卸载的是当前正在使用的 就设置为刚刚的最新版本
+	if current.Equal(from) {
+		if err := m.Use(s.To); err != nil {
+			return installed, err
+		}
+	}
+	return installed, nil
+}
+
+// Upgrade 生成并执行升级计划，未指定版本时升级所有可升级的版本
+func (m *Manager) Upgrade(ctx context.Context, opts UpgradeOptions, versions ...string) (*UpgradeResult, error) {
+	steps, err := m.PlanUpgrade(opts, versions...)
+	if err != nil {
+		return nil, err
+	}
+	return m.ApplyUpgrade(ctx, steps, opts), nil
+}
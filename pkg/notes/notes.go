@@ -0,0 +1,135 @@
+package notes
+
+import (
+	"encoding/json"
+	"html"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/serious-snow/govm/pkg/utils/httpc"
+	"github.com/serious-snow/govm/pkg/version"
+)
+
+// Link go 的发布历史页面
+const Link = "https://go.dev/doc/devel/release"
+
+// Note 一个版本的发布说明
+type Note struct {
+	Version version.Version `json:"version"`
+	Date    string          `json:"date,omitempty"`
+	Text    string          `json:"text"`
+}
+
+var (
+	// 补丁版本：<p id="go1.21.5">go1.21.5 (released 2023-12-05) includes ...</p>
+	patchReg = regexp.MustCompile(`(?s)<p id="go([0-9][0-9a-z.]*)">(.*?)</p>`)
+	// 主版本：<h2 id="go1.21.0">go1.21.0 (released 2023-08-08)</h2> <p>Go 1.21.0 is a major release ...</p>
+	majorReg = regexp.MustCompile(`(?s)<h2 id="go([0-9][0-9a-z.]*)">(.*?)</h2>\s*<p>(.*?)</p>`)
+	dateReg  = regexp.MustCompile(`\(released (\d{4}-\d{2}-\d{2})\)`)
+	tagReg   = regexp.MustCompile(`<[^>]*>`)
+	spaceReg = regexp.MustCompile(`\s+`)
+)
+
+// Parse 解析发布历史页面，按版本从新到旧排序
+func Parse(page []byte) []*Note {
+	seen := map[string]struct{}{}
+	notes := make([]*Note, 0)
+	add := func(ver, text string) {
+		v := version.New(ver)
+		if !v.Valid() {
+			return
+		}
+		if _, ok := seen[v.String()]; ok {
+			return
+		}
+		seen[v.String()] = struct{}{}
+
+		text = strings.TrimSpace(spaceReg.ReplaceAllString(html.UnescapeString(tagReg.ReplaceAllString(text, "")), " "))
+		note := &Note{Version: *v, Text: text}
+		if match := dateReg.FindStringSubmatch(text); match != nil {
+			note.Date = match[1]
+		}
+		notes = append(notes, note)
+	}
+
+	for _, match := range patchReg.FindAllSubmatch(page, -1) {
+		add(string(match[1]), string(match[2]))
+	}
+	for _, match := range majorReg.FindAllSubmatch(page, -1) {
+		add(string(match[1]), string(match[2])+" "+string(match[3]))
+	}
+
+	sort.Slice(notes, func(i, j int) bool {
+		return notes[i].Version.Greater(notes[j].Version)
+	})
+	return notes
+}
+
+// Fetch 获取并解析发布历史页面
+func Fetch() ([]*Note, error) {
+	page, err := httpc.Get(Link)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(page), nil
+}
+
+// Load 读取缓存的发布说明，文件不存在时返回空列表
+func Load(file string) ([]*Note, error) {
+	buf, err := os.ReadFile(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var notes []*Note
+	if err := json.Unmarshal(buf, &notes); err != nil {
+		return nil, err
+	}
+	return notes, nil
+}
+
+// Save 缓存发布说明
+func Save(file string, notes []*Note) error {
+	buf, err := json.Marshal(notes)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, buf, 0o644)
+}
+
+// Find 查找版本的发布说明，找不到时返回 nil
+func Find(notes []*Note, v version.Version) *Note {
+	for _, note := range notes {
+		if note.Version.Equal(v) {
+			return note
+		}
+	}
+	return nil
+}
+
+// Between 版本大于 from 且不大于 to 的发布说明，即从 from 升级到 to 跳过的版本，按版本从旧到新排序
+func Between(notes []*Note, from, to version.Version) []*Note {
+	result := make([]*Note, 0)
+	for i := len(notes) - 1; i >= 0; i-- {
+		v := notes[i].Version
+		if v.Greater(from) && !v.Greater(to) {
+			result = append(result, notes[i])
+		}
+	}
+	return result
+}
+
+// Minor 次版本（如 1.21）的所有发布说明，按版本从旧到新排序
+func Minor(notes []*Note, minor string) []*Note {
+	result := make([]*Note, 0)
+	for i := len(notes) - 1; i >= 0; i-- {
+		if notes[i].Version.MinorVersion() == minor {
+			result = append(result, notes[i])
+		}
+	}
+	return result
+}
@@ -0,0 +1,63 @@
+package notes
+
+import (
+	"testing"
+
+	"github.com/serious-snow/govm/pkg/version"
+)
+
+const page = `
+<h2 id="go1.22.0">go1.22.0 (released 2024-02-06)</h2>
+
+<p>
+Go 1.22.0 is a major release of Go.
+Read the <a href="/doc/go1.22">Go 1.22 Release Notes</a> for more information.
+</p>
+
+<h3 id="go1.21.minor">Minor revisions</h3>
+
+<p id="go1.21.1">
+go1.21.1 (released 2023-09-06) includes four security fixes to the <code>cmd/go</code>,
+<code>crypto/tls</code>, and <code>html/template</code> packages.
+</p>
+
+<p id="go1.21.2">
+go1.21.2 (released 2023-10-05) includes one security fix to the <code>cmd/go</code> package &amp; bug fixes.
+</p>
+
+<h2 id="go1.21.0">go1.21.0 (released 2023-08-08)</h2>
+
+<p>
+Go 1.21.0 is a major release of Go.
+</p>
+`
+
+func TestParse(t *testing.T) {
+	notes := Parse([]byte(page))
+	if len(notes) != 4 {
+		t.Fatalf("want 4 notes, got %d", len(notes))
+	}
+	if got := notes[0].Version.String(); got != "1.22.0" {
+		t.Errorf("want newest 1.22.0, got %s", got)
+	}
+
+	note := Find(notes, *version.New("1.21.2"))
+	if note == nil {
+		t.Fatal("1.21.2 not found")
+	}
+	if note.Date != "2023-10-05" {
+		t.Errorf("want date 2023-10-05, got %q", note.Date)
+	}
+	want := "go1.21.2 (released 2023-10-05) includes one security fix to the cmd/go package & bug fixes."
+	if note.Text != want {
+		t.Errorf("text = %q, want %q", note.Text, want)
+	}
+
+	between := Between(notes, *version.New("1.21.0"), *version.New("1.21.2"))
+	if len(between) != 2 || between[0].Version.String() != "1.21.1" || between[1].Version.String() != "1.21.2" {
+		t.Errorf("Between = %v", between)
+	}
+	if minor := Minor(notes, "1.21"); len(minor) != 3 {
+		t.Errorf("want 3 notes of 1.21, got %d", len(minor))
+	}
+}
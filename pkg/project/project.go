@@ -0,0 +1,114 @@
+package project
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/serious-snow/govm/pkg/manager"
+)
+
+const (
+	GoVersionFile = ".go-version"
+	GoModFile     = "go.mod"
+)
+
+var ErrNotFound = errors.New("未找到项目要求的 go 版本")
+
+// Detect 从 dir 开始逐级向上查找项目要求的 go 版本，
+// 同一目录下 .go-version 优先于 go.mod，go.mod 中 toolchain 指令优先于 go 指令，
+// 返回版本和来源文件
+func Detect(dir string) (ver, file string, err error) {
+	dir, err = filepath.Abs(dir)
+	if err != nil {
+		return "", "", err
+	}
+	for {
+		ver, file, err = Read(dir)
+		if err == nil || !errors.Is(err, ErrNotFound) {
+			return ver, file, err
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", "", ErrNotFound
+		}
+		dir = parent
+	}
+}
+
+// Pinned Read、Detect 返回的来源文件是否固定了具体版本，即 .go-version 或 go.mod 中的 toolchain 指令，
+// go.mod 中的 go 指令只是最低版本
+func Pinned(file string) bool {
+	if filepath.Base(file) == GoVersionFile {
+		return true
+	}
+	buf, err := os.ReadFile(file)
+	if err != nil {
+		return false
+	}
+	_, toolchain := ParseGoMod(buf)
+	return toolchain != ""
+}
+
+// Read 读取 dir 目录下项目要求的 go 版本，不向上查找
+func Read(dir string) (ver, file string, err error) {
+	file = filepath.Join(dir, GoVersionFile)
+	if buf, err := os.ReadFile(file); err == nil {
+		if ver = ParseGoVersionFile(buf); ver != "" {
+			return ver, file, nil
+		}
+	}
+
+	file = filepath.Join(dir, GoModFile)
+	if buf, err := os.ReadFile(file); err == nil {
+		goVersion, toolchain := ParseGoMod(buf)
+		if toolchain != "" {
+			return toolchain, file, nil
+		}
+		if goVersion != "" {
+			return goVersion, file, nil
+		}
+	}
+	return "", "", ErrNotFound
+}
+
+// ParseGoVersionFile 解析 .go-version，取第一个非空、非注释行
+func ParseGoVersionFile(buf []byte) string {
+	scanner := bufio.NewScanner(bytes.NewReader(buf))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		return manager.TrimVersion(line)
+	}
+	return ""
+}
+
+// ParseGoMod 解析 go.mod 中的 go 和 toolchain 指令
+func ParseGoMod(buf []byte) (goVersion, toolchain string) {
+	scanner := bufio.NewScanner(bytes.NewReader(buf))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		switch fields[0] {
+		case "go":
+			goVersion = manager.TrimVersion(fields[1])
+		case "toolchain":
+			if fields[1] != "default" {
+				toolchain = manager.TrimVersion(fields[1])
+			}
+		}
+	}
+	return goVersion, toolchain
+}
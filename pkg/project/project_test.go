@@ -0,0 +1,118 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseGoMod(t *testing.T) {
+	goVersion, toolchain := ParseGoMod([]byte(`module example.com/foo
+
+go 1.21 // minimum
+
+toolchain go1.22.1
+`))
+	if goVersion != "1.21" || toolchain != "1.22.1" {
+		t.Errorf("got %q %q", goVersion, toolchain)
+	}
+}
+
+func TestDetect(t *testing.T) {
+	root := t.TempDir()
+	sub := filepath.Join(root, "a", "b")
+	if err := os.MkdirAll(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "go.mod"), []byte("module foo\n\ngo 1.20.3\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	ver, file, err := Detect(sub)
+	if err != nil || ver != "1.20.3" || file != filepath.Join(root, "go.mod") {
+		t.Fatalf("got %q %q %v", ver, file, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(root, "a", ".go-version"), []byte("# pinned\ngo1.22\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ver, _, err = Detect(sub)
+	if err != nil || ver != "1.22" {
+		t.Fatalf("got %q %v", ver, err)
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	root := t.TempDir()
+	write := func(name, content string) {
+		name = filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("a/go.mod", "module a\n\ngo 1.21.3\n")
+	write("b/.go-version", "1.22\n")
+	write("b/vendor/c/go.mod", "module c\n\ngo 1.19\n")
+	write(".git/d/go.mod", "module d\n\ngo 1.18\n")
+
+	dirs, err := Scan(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dirs) != 2 || dirs[0] != filepath.Join(root, "a") || dirs[1] != filepath.Join(root, "b") {
+		t.Fatalf("Scan = %v", dirs)
+	}
+
+	file := filepath.Join(root, "projects.json")
+	r, err := LoadRegistry(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, dir := range dirs {
+		if _, err := r.Add(dir); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := r.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	write("a/go.mod", "module a\n\ngo 1.22.0\n")
+	if err := os.RemoveAll(filepath.Join(root, "b")); err != nil {
+		t.Fatal(err)
+	}
+	r, err = LoadRegistry(file)
+	if err != nil || len(r.Projects) != 2 {
+		t.Fatalf("LoadRegistry = %v, %v", r.Projects, err)
+	}
+	gone := r.Refresh()
+	if len(gone) != 1 || gone[0].Dir != filepath.Join(root, "b") || r.Projects[0].Version != "1.22.0" {
+		t.Fatalf("Refresh gone = %v, projects = %v", gone, r.Projects)
+	}
+	if !r.Remove(filepath.Join(root, "b")) || len(r.Projects) != 1 {
+		t.Fatalf("Remove failed: %v", r.Projects)
+	}
+}
+
+func TestPinned(t *testing.T) {
+	dir := t.TempDir()
+	mod := filepath.Join(dir, GoModFile)
+	if err := os.WriteFile(mod, []byte("module foo\n\ngo 1.22.0\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if Pinned(mod) {
+		t.Error("go directive should not be pinned")
+	}
+	if err := os.WriteFile(mod, []byte("module foo\n\ngo 1.22.0\n\ntoolchain go1.22.3\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if !Pinned(mod) {
+		t.Error("toolchain directive should be pinned")
+	}
+	if !Pinned(filepath.Join(dir, GoVersionFile)) {
+		t.Error(".go-version should be pinned")
+	}
+}
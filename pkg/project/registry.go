@@ -0,0 +1,136 @@
+package project
+
+import (
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Project 登记的项目
+type Project struct {
+	Dir     string    `json:"dir"`
+	Version string    `json:"version"` // 项目要求的 go 版本
+	File    string    `json:"file"`    // 声明版本的文件，go.mod 或 .go-version
+	AddedAt time.Time `json:"addedAt"`
+}
+
+// Registry 项目登记表，记录本机项目要求的 go 版本，卸载、清理时用于判断版本是否仍被使用
+type Registry struct {
+	file     string
+	Projects []*Project
+}
+
+// LoadRegistry 读取登记表，文件不存在时返回空的登记表
+func LoadRegistry(file string) (*Registry, error) {
+	r := &Registry{file: file}
+	buf, err := os.ReadFile(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return r, nil
+		}
+		return r, err
+	}
+	if err := json.Unmarshal(buf, &r.Projects); err != nil {
+		return r, err
+	}
+	return r, nil
+}
+
+func (r *Registry) Save() error {
+	buf, err := json.MarshalIndent(r.Projects, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(r.file, buf, 0o644)
+}
+
+// Add 登记 dir 所在的项目，从 dir 开始逐级向上查找要求的版本，已登记的项目会被更新
+func (r *Registry) Add(dir string) (*Project, error) {
+	ver, file, err := Detect(dir)
+	if err != nil {
+		return nil, err
+	}
+	p := &Project{
+		Dir:     filepath.Dir(file),
+		Version: ver,
+		File:    file,
+		AddedAt: time.Now(),
+	}
+	for i := range r.Projects {
+		if r.Projects[i].Dir == p.Dir {
+			p.AddedAt = r.Projects[i].AddedAt
+			r.Projects[i] = p
+			return p, nil
+		}
+	}
+	r.Projects = append(r.Projects, p)
+	sort.Slice(r.Projects, func(i, j int) bool {
+		return r.Projects[i].Dir < r.Projects[j].Dir
+	})
+	return p, nil
+}
+
+// Remove 取消登记，返回是否登记过
+func (r *Registry) Remove(dir string) bool {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return false
+	}
+	for i := range r.Projects {
+		if r.Projects[i].Dir == dir {
+			r.Projects = append(r.Projects[:i], r.Projects[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Refresh 重新读取项目要求的版本，返回已不存在或不再声明版本的项目，这些项目不会被删除
+func (r *Registry) Refresh() []*Project {
+	gone := make([]*Project, 0)
+	for _, p := range r.Projects {
+		ver, file, err := Read(p.Dir)
+		if err != nil {
+			gone = append(gone, p)
+			continue
+		}
+		p.Version, p.File = ver, file
+	}
+	return gone
+}
+
+// Scan 查找 root 下所有声明了 go 版本的目录，跳过隐藏目录、vendor、testdata 和 node_modules
+func Scan(root string) ([]string, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	dirs := make([]string, 0)
+	err = filepath.WalkDir(root, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// 没有权限的目录直接跳过
+			if d != nil && d.IsDir() && name != root {
+				return fs.SkipDir
+			}
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if name != root {
+			switch base := d.Name(); {
+			case strings.HasPrefix(base, "."), base == "vendor", base == "testdata", base == "node_modules":
+				return fs.SkipDir
+			}
+		}
+		if _, _, err := Read(name); err == nil {
+			dirs = append(dirs, name)
+		}
+		return nil
+	})
+	return dirs, err
+}
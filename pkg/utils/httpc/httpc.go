@@ -1,6 +1,7 @@
 package httpc
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
@@ -20,7 +21,16 @@ var client = &http.Client{
 }
 
 func Get(url string) ([]byte, error) {
-	resp, err := client.Get(url)
+	return GetContext(context.Background(), url)
+}
+
+// GetContext 同 Get，ctx 取消时中断请求
+func GetContext(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
 	if resp != nil {
 		defer resp.Body.Close()
 	}
@@ -33,7 +43,33 @@ func Get(url string) ([]byte, error) {
 	return io.ReadAll(resp.Body)
 }
 
-func Download(url, dir, fileName, sha256v string) (returnErr error) {
+const barTemplate = `{{ string . "prefix" }}{{ bar . "[" "=" ">" " " "]"}} {{counters .}} {{speed . }} {{percent .}}`
+
+// ProgressFunc 返回下载使用的进度条，total 为文件大小，未知时为 -1
+type ProgressFunc func(total int64) *pb.ProgressBar
+
+// NewBar 创建未启动的下载进度条，prefix 显示在进度条前面
+func NewBar(total int64, prefix string) *pb.ProgressBar {
+	bar := pb.New64(total)
+	bar.SetTemplateString(barTemplate)
+	bar.Set(pb.Bytes, true)
+	bar.Set(pb.SIBytesPrefix, true)
+	bar.Set("prefix", prefix)
+	return bar
+}
+
+// StartBar 默认的进度条，单独输出到终端
+func StartBar(total int64) *pb.ProgressBar {
+	return NewBar(total, "").Start()
+}
+
+func Download(ctx context.Context, url, dir, fileName, sha256v string) error {
+	return DownloadWithProgress(ctx, url, dir, fileName, sha256v, StartBar)
+}
+
+// DownloadWithProgress 下载文件，使用 progress 返回的进度条显示进度，如多个下载共用的进度条池中的进度条，
+// ctx 取消时中断下载
+func DownloadWithProgress(ctx context.Context, url, dir, fileName, sha256v string, progress ProgressFunc) (returnErr error) {
 	if returnErr = path.MakeDir(dir); returnErr != nil {
 		return
 	}
@@ -52,10 +88,18 @@ func Download(url, dir, fileName, sha256v string) (returnErr error) {
 		_ = file.Close()
 		if rename {
 			returnErr = os.Rename(tempFileName, newFileName)
+			return
 		}
+		// 下载失败时删除未完成的文件，避免残留
+		_ = os.Remove(tempFileName)
 	}()
 
-	resp, err := client.Get(url)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		returnErr = err
+		return
+	}
+	resp, err := client.Do(req)
 	if resp != nil {
 		defer resp.Body.Close()
 	}
@@ -70,11 +114,8 @@ func Download(url, dir, fileName, sha256v string) (returnErr error) {
 	}
 
 	// 进度条
-	bar := pb.Full.Start64(resp.ContentLength)
-	tmpl := `{{ bar . "[" "=" ">" " " "]"}} {{counters .}} {{speed . }} {{percent .}}`
-	bar.SetTemplateString(tmpl)
-	bar.Set(pb.Bytes, true)
-	bar.Set(pb.SIBytesPrefix, true)
+	bar := progress(resp.ContentLength)
+	bar.SetTotal(resp.ContentLength)
 	defer bar.Finish()
 
 	sha := sha256.New()
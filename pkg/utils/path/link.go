@@ -0,0 +1,17 @@
+//go:build !windows
+
+package path
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+// LinkInfo 文件的 inode 和硬链接数，系统不支持时 ok 为 false
+func LinkInfo(info fs.FileInfo) (ino, nlink uint64, ok bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, 0, false
+	}
+	return uint64(st.Ino), uint64(st.Nlink), true
+}
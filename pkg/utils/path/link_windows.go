@@ -0,0 +1,12 @@
+//go:build windows
+
+package path
+
+import (
+	"io/fs"
+)
+
+// LinkInfo 文件的 inode 和硬链接数，windows 下无法获取，ok 为 false
+func LinkInfo(fs.FileInfo) (ino, nlink uint64, ok bool) {
+	return 0, 0, false
+}
@@ -32,15 +32,24 @@ func MakeDir(dir string) error {
 }
 
 func Decompress(from, to string) error {
+	return DecompressFilter(from, to, nil)
+}
+
+// DecompressFilter 解压归档文件，跳过 skip 返回 true 的文件和文件夹，skip 为空时全部解压
+func DecompressFilter(from, to string, skip func(name string) bool) error {
 	switch filepath.Ext(from) {
 	case ".zip":
-		return UnZip(from, to)
+		return unZip(from, to, skip)
 	default:
-		return DecompressTar(from, to)
+		return decompressTar(from, to, skip)
 	}
 }
 
 func DecompressTar(from, to string) error {
+	return decompressTar(from, to, nil)
+}
+
+func decompressTar(from, to string, skip func(name string) bool) error {
 	fr, err := os.Open(from)
 	if err != nil {
 		return err
@@ -65,6 +74,9 @@ func DecompressTar(from, to string) error {
 		if err != nil {
 			return err
 		}
+		if skip != nil && skip(h.Name) {
+			continue
+		}
 		if h.FileInfo().IsDir() {
 			err = MakeDir(filepath.Join(to, h.Name))
 			if err != nil {
@@ -92,6 +104,10 @@ func DecompressTar(from, to string) error {
 }
 
 func UnZip(from, to string) error {
+	return unZip(from, to, nil)
+}
+
+func unZip(from, to string, skip func(name string) bool) error {
 	zr, err := zip.OpenReader(from)
 	if err != nil {
 		return err
@@ -101,6 +117,9 @@ func UnZip(from, to string) error {
 
 	// 读取文件
 	for _, file := range zr.File {
+		if skip != nil && skip(file.Name) {
+			continue
+		}
 		if file.FileInfo().IsDir() {
 			err = MakeDir(filepath.Join(to, file.Name))
 			if err != nil {
@@ -145,3 +164,76 @@ func createFile(name string, flag int, perm os.FileMode) (*os.File, error) {
 	}
 	return os.OpenFile(name, flag, perm)
 }
+
+// WalkArchive 依次读取 tar.gz 或 zip 归档文件中的普通文件，fn 返回错误时停止
+func WalkArchive(from string, fn func(name string, r io.Reader) error) error {
+	if filepath.Ext(from) == ".zip" {
+		zr, err := zip.OpenReader(from)
+		if err != nil {
+			return err
+		}
+		defer zr.Close()
+		for _, file := range zr.File {
+			if !file.Mode().IsRegular() {
+				continue
+			}
+			r, err := file.Open()
+			if err != nil {
+				return err
+			}
+			err = fn(file.Name, r)
+			r.Close()
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	fr, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer fr.Close()
+	gr, err := gzip.NewReader(fr)
+	if err != nil {
+		return err
+	}
+	defer gr.Close()
+	tr := tar.NewReader(gr)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if h.Typeflag != tar.TypeReg {
+			continue
+		}
+		if err := fn(h.Name, tr); err != nil {
+			return err
+		}
+	}
+}
+
+// DecompressFiles 只解压归档文件中 names 包含的文件，用于修复被修改或删除的文件
+func DecompressFiles(from, to string, names map[string]bool) error {
+	return WalkArchive(from, func(name string, r io.Reader) error {
+		if !names[name] {
+			return nil
+		}
+		// 先删除再创建，文件可能是与其他版本共享的硬链接，直接覆盖会修改其他版本的文件
+		if err := os.Remove(filepath.Join(to, name)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		fw, err := createFile(filepath.Join(to, name), os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o755)
+		if err != nil {
+			return err
+		}
+		defer fw.Close()
+		_, err = io.Copy(fw, r)
+		return err
+	})
+}